@@ -0,0 +1,185 @@
+package csfloat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// AuctionDetails is the state of an auction.
+type AuctionDetails struct {
+	ReservePrice uint `json:"reserve_price,omitzero"`
+	// TopBid is nil if nobody has bid yet.
+	TopBid    *Bid      `json:"top_bid,omitempty"`
+	ExpiresAt time.Time `json:"expires_at"`
+	// MinNextBid is the lowest max price a new bid has to have.
+	MinNextBid uint `json:"min_next_bid"`
+	BidCount   uint `json:"bid_count,omitzero"`
+}
+
+// ReserveMet reports whether the top bid reaches the reserve price. If the
+// reserve price isn't met when the auction ends, the item isn't sold.
+func (details *AuctionDetails) ReserveMet() bool {
+	return details.TopBid != nil && details.TopBid.Price >= details.ReservePrice
+}
+
+// Ended reports whether the auction has expired.
+func (details *AuctionDetails) Ended() bool {
+	return !time.Now().Before(details.ExpiresAt)
+}
+
+type Bid struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	// Price is the current price of the bid, which is at most the max price
+	// the bidder specified.
+	Price             uint   `json:"price"`
+	ContractID        string `json:"contract_id"`
+	ObfuscatedBuyerID string `json:"obfuscated_buyer_id,omitempty"`
+}
+
+type PlaceBidResponse struct {
+	GenericResponse
+	Bid
+}
+
+func (response *PlaceBidResponse) responseBody() any {
+	return response
+}
+
+// PlaceBid bids on an auction. The bid is raised automatically when outbid,
+// up to maxPrice, which has to be at least AuctionDetails.MinNextBid.
+func (api *API) PlaceBid(listingId string, maxPrice uint) (*PlaceBidResponse, error) {
+	return api.PlaceBidContext(context.Background(), listingId, maxPrice)
+}
+
+// PlaceBidContext is like PlaceBid, but uses ctx for the request.
+func (api *API) PlaceBidContext(ctx context.Context, listingId string, maxPrice uint) (*PlaceBidResponse, error) {
+	return handleRequest(
+		ctx,
+		api,
+		RatelimitKeyPlaceBid,
+		api.httpClient,
+		http.MethodPost,
+		"/listings/"+listingId+"/bid",
+		api.apiKey,
+		map[string]any{
+			"max_price": maxPrice,
+		},
+		nil,
+		&PlaceBidResponse{},
+	)
+}
+
+type BidsResponse struct {
+	GenericResponse
+	Data []Bid
+}
+
+func (response *BidsResponse) responseBody() any {
+	return &response.Data
+}
+
+// Bids returns all bids on the given auction, newest first.
+func (api *API) Bids(listingId string) (*BidsResponse, error) {
+	return api.BidsContext(context.Background(), listingId)
+}
+
+// BidsContext is like Bids, but uses ctx for the request.
+func (api *API) BidsContext(ctx context.Context, listingId string) (*BidsResponse, error) {
+	return handleRequest(
+		ctx,
+		api,
+		RatelimitKeyGetBids,
+		api.httpClient,
+		http.MethodGet,
+		"/listings/"+listingId+"/bids",
+		api.apiKey,
+		nil,
+		nil,
+		&BidsResponse{},
+	)
+}
+
+// MyBid is one of our own bids.
+type MyBid struct {
+	Bid
+	MaxPrice uint `json:"max_price"`
+	// Contract is the auction, as of the time of the request.
+	Contract ActiveListing `json:"contract"`
+}
+
+type MyBidsResponse struct {
+	GenericResponse
+	Data []MyBid
+}
+
+func (response *MyBidsResponse) responseBody() any {
+	return &response.Data
+}
+
+// MyBids returns our bids on all auctions, newest first.
+func (api *API) MyBids() (*MyBidsResponse, error) {
+	return api.MyBidsContext(context.Background())
+}
+
+// MyBidsContext is like MyBids, but uses ctx for the request.
+func (api *API) MyBidsContext(ctx context.Context) (*MyBidsResponse, error) {
+	return handleRequest(
+		ctx,
+		api,
+		RatelimitKeyGetMyBids,
+		api.httpClient,
+		http.MethodGet,
+		"/me/bids",
+		api.apiKey,
+		nil,
+		nil,
+		&MyBidsResponse{},
+	)
+}
+
+// These errors are returned by ScheduleBid.
+var (
+	ErrNotAnAuction   = errors.New("not an auction")
+	ErrAuctionEnded   = errors.New("auction ended")
+	ErrMaxPriceTooLow = errors.New("max price below minimum next bid")
+)
+
+// ScheduleBid blocks until offset before the given auction ends and then
+// places a bid with maxPrice. Bidding late doesn't leave other bidders time to
+// react. As auctions can be extended, the listing is fetched again once the
+// time has come, waiting longer if necessary. If the minimum next bid exceeds
+// maxPrice by then, no bid is placed and ErrMaxPriceTooLow is returned.
+//
+// To bid on multiple auctions, call ScheduleBid in a goroutine per auction.
+func (api *API) ScheduleBid(ctx context.Context, listingId string, maxPrice uint, offset time.Duration) (*PlaceBidResponse, error) {
+	for {
+		response, err := api.ListingContext(ctx, listingId)
+		if err != nil {
+			return nil, fmt.Errorf("error getting listing: %w", err)
+		}
+		listing := response.Item
+		if listing.Type != Auction || listing.AuctionDetails == nil {
+			return nil, fmt.Errorf("%w: %s", ErrNotAnAuction, listingId)
+		}
+		details := listing.AuctionDetails
+		if listing.State != ListingStateListed || details.Ended() {
+			return nil, fmt.Errorf("%w: %s", ErrAuctionEnded, listingId)
+		}
+
+		if wait := time.Until(details.ExpiresAt.Add(-offset)); wait > 0 {
+			if err := sleep(ctx, wait); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if details.MinNextBid > maxPrice {
+			return nil, fmt.Errorf("%w: %d > %d", ErrMaxPriceTooLow, details.MinNextBid, maxPrice)
+		}
+		return api.PlaceBidContext(ctx, listingId, maxPrice)
+	}
+}
@@ -0,0 +1,101 @@
+package csfloat_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	csfloat "github.com/Bios-Marcel/csfloat_go"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Auction(t *testing.T) {
+	ass := assert.New(t)
+	server, api := newFakeServer(t)
+	listing := server.AddListing(csfloat.ActiveListing{
+		Price: 1000,
+		Type:  csfloat.Auction,
+		AuctionDetails: &csfloat.AuctionDetails{
+			ReservePrice: 1200,
+			ExpiresAt:    time.Now().Add(time.Hour),
+		},
+	})
+
+	bid, err := api.PlaceBid(listing.ID, 1500)
+	if !ass.NoError(err) {
+		return
+	}
+	ass.Equal(uint(1000), bid.Price)
+
+	response, err := api.Listing(listing.ID)
+	if ass.NoError(err) && ass.NotNil(response.Item.AuctionDetails) {
+		details := response.Item.AuctionDetails
+		ass.Equal(bid.ID, details.TopBid.ID)
+		ass.Equal(uint(1), details.BidCount)
+		ass.Equal(uint(1010), details.MinNextBid)
+		ass.False(details.ReserveMet())
+	}
+
+	// Outbid by another user, our bid is raised automatically.
+	_, err = server.AddBid(listing.ID, 1300)
+	ass.NoError(err)
+	response, err = api.Listing(listing.ID)
+	if ass.NoError(err) {
+		details := response.Item.AuctionDetails
+		ass.Equal(bid.ID, details.TopBid.ID)
+		ass.Equal(uint(1313), details.TopBid.Price)
+		ass.True(details.ReserveMet())
+	}
+
+	bids, err := api.Bids(listing.ID)
+	if ass.NoError(err) {
+		ass.Len(bids.Data, 2)
+	}
+	myBids, err := api.MyBids()
+	if ass.NoError(err) && ass.Len(myBids.Data, 1) {
+		ass.Equal(uint(1500), myBids.Data[0].MaxPrice)
+		ass.Equal(listing.ID, myBids.Data[0].Contract.ID)
+	}
+
+	_, err = api.PlaceBid(listing.ID, 1000)
+	ass.Error(err)
+}
+
+func Test_ScheduleBid(t *testing.T) {
+	ass := assert.New(t)
+	server, api := newFakeServer(t)
+	expiresAt := time.Now().Add(300 * time.Millisecond)
+	auction := server.AddListing(csfloat.ActiveListing{
+		Price:          1000,
+		Type:           csfloat.Auction,
+		AuctionDetails: &csfloat.AuctionDetails{ExpiresAt: expiresAt},
+	})
+
+	bid, err := api.ScheduleBid(context.Background(), auction.ID, 1500, 100*time.Millisecond)
+	if ass.NoError(err) {
+		ass.Equal(uint(1000), bid.Price)
+		ass.False(time.Now().Before(expiresAt.Add(-100 * time.Millisecond)))
+	}
+
+	pending := server.AddListing(csfloat.ActiveListing{
+		Price:          1000,
+		Type:           csfloat.Auction,
+		AuctionDetails: &csfloat.AuctionDetails{ExpiresAt: time.Now().Add(2 * time.Hour)},
+	})
+	_, err = server.AddBid(pending.ID, 1200)
+	ass.NoError(err)
+	_, err = api.ScheduleBid(context.Background(), pending.ID, 1005, 3*time.Hour)
+	ass.ErrorIs(err, csfloat.ErrMaxPriceTooLow)
+
+	ended := server.AddListing(csfloat.ActiveListing{
+		Price:          1000,
+		Type:           csfloat.Auction,
+		AuctionDetails: &csfloat.AuctionDetails{ExpiresAt: time.Now().Add(-time.Minute)},
+	})
+	_, err = api.ScheduleBid(context.Background(), ended.ID, 1500, time.Minute)
+	ass.ErrorIs(err, csfloat.ErrAuctionEnded)
+
+	buyNow := server.AddListing(csfloat.ActiveListing{Price: 1000})
+	_, err = api.ScheduleBid(context.Background(), buyNow.ID, 1500, time.Minute)
+	ass.ErrorIs(err, csfloat.ErrNotAnAuction)
+}
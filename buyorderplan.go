@@ -0,0 +1,260 @@
+package csfloat
+
+import (
+	"cmp"
+	"context"
+	json "encoding/json/v2"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// DesiredBuyOrder describes a simple buy order as it should exist. See
+// PlanBuyOrders.
+type DesiredBuyOrder struct {
+	// MarketHashName can be built via MarketHashName.String.
+	MarketHashName string `json:"market_hash_name"`
+	MaxPrice       uint   `json:"max_price"`
+	Quantity       uint   `json:"quantity"`
+}
+
+// ErrInvalidDesiredBuyOrders is returned by LoadDesiredBuyOrders and
+// PlanBuyOrders.
+var ErrInvalidDesiredBuyOrders = errors.New("invalid desired buy orders")
+
+// LoadDesiredBuyOrders reads a JSON array of DesiredBuyOrder, for example:
+//
+//	[{"market_hash_name": "AK-47 | Redline (Field-Tested)", "max_price": 900, "quantity": 1}]
+func LoadDesiredBuyOrders(reader io.Reader) ([]DesiredBuyOrder, error) {
+	var desired []DesiredBuyOrder
+	if err := json.UnmarshalRead(reader, &desired); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidDesiredBuyOrders, err)
+	}
+	if err := validateDesiredBuyOrders(desired); err != nil {
+		return nil, err
+	}
+	return desired, nil
+}
+
+// validateDesiredBuyOrders reports all orders without name, price or
+// quantity, and all names occurring more than once.
+func validateDesiredBuyOrders(desired []DesiredBuyOrder) error {
+	var errs []error
+	invalid := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%w: "+format, append([]any{ErrInvalidDesiredBuyOrders}, args...)...))
+	}
+
+	seen := make(map[string]struct{}, len(desired))
+	for index, order := range desired {
+		if order.MarketHashName == "" {
+			invalid("order %d has no market hash name", index)
+			continue
+		}
+		if _, ok := seen[order.MarketHashName]; ok {
+			invalid("duplicate order for %q", order.MarketHashName)
+		}
+		seen[order.MarketHashName] = struct{}{}
+		if order.MaxPrice == 0 {
+			invalid("order for %q has no max price", order.MarketHashName)
+		}
+		if order.Quantity == 0 {
+			invalid("order for %q has no quantity", order.MarketHashName)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// BuyOrderAction is the kind of a BuyOrderChange.
+type BuyOrderAction uint8
+
+const (
+	BuyOrderCreate BuyOrderAction = iota
+	BuyOrderUpdate
+	BuyOrderDelete
+)
+
+func (action BuyOrderAction) String() string {
+	switch action {
+	case BuyOrderCreate:
+		return "create"
+	case BuyOrderUpdate:
+		return "update"
+	default:
+		return "delete"
+	}
+}
+
+// BuyOrderChange is a single step of a BuyOrderPlan.
+type BuyOrderChange struct {
+	Action BuyOrderAction
+	// Current is the live order. It is set for updates and deletes.
+	Current *ItemBuyOrder
+	// Desired is set for creates and updates.
+	Desired *DesiredBuyOrder
+}
+
+func (change BuyOrderChange) marketHashName() string {
+	if change.Desired != nil {
+		return change.Desired.MarketHashName
+	}
+	return change.Current.MarketHashName
+}
+
+// String describes the change in a single line, marking creates with "+",
+// updates with "~" and deletes with "-".
+func (change BuyOrderChange) String() string {
+	switch change.Action {
+	case BuyOrderCreate:
+		return fmt.Sprintf("+ %s: price %d, quantity %d",
+			change.Desired.MarketHashName, change.Desired.MaxPrice, change.Desired.Quantity)
+	case BuyOrderUpdate:
+		return fmt.Sprintf("~ %s: price %s, quantity %s",
+			change.Desired.MarketHashName,
+			transition(change.Current.Price, change.Desired.MaxPrice),
+			transition(change.Current.Quantity, change.Desired.Quantity))
+	default:
+		return fmt.Sprintf("- %s: price %d, quantity %d",
+			change.Current.MarketHashName, change.Current.Price, change.Current.Quantity)
+	}
+}
+
+func transition(current, desired uint) string {
+	if current == desired {
+		return fmt.Sprint(current)
+	}
+	return fmt.Sprintf("%d -> %d", current, desired)
+}
+
+// BuyOrderPlan is the set of changes required to get from the live buy orders
+// to the desired ones. Print it for a dry run, apply it via
+// ApplyBuyOrderPlan.
+type BuyOrderPlan struct {
+	// Changes are ordered by action, deletes first, and then by market hash
+	// name. Deleting first frees up the balance reserved by the orders.
+	Changes []BuyOrderChange
+}
+
+// Count returns the amount of changes with the given action.
+func (plan *BuyOrderPlan) Count(action BuyOrderAction) int {
+	var count int
+	for _, change := range plan.Changes {
+		if change.Action == action {
+			count++
+		}
+	}
+	return count
+}
+
+// String prints one line per change, followed by a summary.
+func (plan *BuyOrderPlan) String() string {
+	return formatPlan(plan.Changes, fmt.Sprintf("%d to create, %d to update, %d to delete",
+		plan.Count(BuyOrderCreate), plan.Count(BuyOrderUpdate), plan.Count(BuyOrderDelete)))
+}
+
+// PlanBuyOrders compares the desired simple buy orders with our live ones and
+// returns the required changes, without applying them. Live simple orders
+// for items that aren't desired are deleted. If there are multiple live
+// orders for the same item, all but one are deleted. Advanced buy orders are
+// never touched.
+func (api *API) PlanBuyOrders(ctx context.Context, desired []DesiredBuyOrder) (*BuyOrderPlan, error) {
+	if err := validateDesiredBuyOrders(desired); err != nil {
+		return nil, err
+	}
+
+	live := make(map[string][]ItemBuyOrder)
+	for order, err := range api.AllMyBuyOrders(ctx, MyBuyOrdersRequest{}) {
+		if err != nil {
+			return nil, fmt.Errorf("error getting buy orders: %w", err)
+		}
+		if order.Expression == "" {
+			live[order.MarketHashName] = append(live[order.MarketHashName], order)
+		}
+	}
+
+	plan := &BuyOrderPlan{}
+	for _, order := range desired {
+		orders := live[order.MarketHashName]
+		delete(live, order.MarketHashName)
+		if len(orders) == 0 {
+			plan.Changes = append(plan.Changes, BuyOrderChange{Action: BuyOrderCreate, Desired: &order})
+			continue
+		}
+
+		if orders[0].Price != order.MaxPrice || orders[0].Quantity != order.Quantity {
+			plan.Changes = append(plan.Changes, BuyOrderChange{
+				Action:  BuyOrderUpdate,
+				Current: &orders[0],
+				Desired: &order,
+			})
+		}
+		for index := range orders[1:] {
+			plan.Changes = append(plan.Changes, BuyOrderChange{Action: BuyOrderDelete, Current: &orders[index+1]})
+		}
+	}
+	for _, orders := range live {
+		for index := range orders {
+			plan.Changes = append(plan.Changes, BuyOrderChange{Action: BuyOrderDelete, Current: &orders[index]})
+		}
+	}
+
+	slices.SortStableFunc(plan.Changes, func(a, b BuyOrderChange) int {
+		// Deletes first, then updates, then creates.
+		if order := cmp.Compare(b.Action, a.Action); order != 0 {
+			return order
+		}
+		if order := strings.Compare(a.marketHashName(), b.marketHashName()); order != 0 {
+			return order
+		}
+		// Only duplicate live orders share a name, so Current is set.
+		return strings.Compare(a.Current.ID, b.Current.ID)
+	})
+	return plan, nil
+}
+
+// ApplyBuyOrderPlan applies the changes of the plan in order. Creates use
+// CreateSimpleBuyOrder, deletes DeleteBuyOrder and updates UpdateBuyOrder, so
+// updated orders keep their ID. Before each request, the SuggestedWait of the
+// respective bucket is awaited, so large plans stay within the ratelimits.
+//
+// Orders that are already gone count as deleted. On the first error, applying
+// stops and the changes applied so far are returned along with the error.
+func (api *API) ApplyBuyOrderPlan(ctx context.Context, plan *BuyOrderPlan) ([]BuyOrderChange, error) {
+	return applyPlan(plan.Changes, func(change BuyOrderChange) error {
+		return api.applyBuyOrderChange(ctx, change)
+	})
+}
+
+func (api *API) applyBuyOrderChange(ctx context.Context, change BuyOrderChange) error {
+	switch change.Action {
+	case BuyOrderCreate:
+		if err := api.awaitSuggestedWait(ctx, RatelimitKeyCreateBuyOrder); err != nil {
+			return err
+		}
+		_, err := api.CreateSimpleBuyOrderContext(ctx, CreateSimpleBuyOrderPayload{
+			MarketHashName: change.Desired.MarketHashName,
+			MaxPrice:       change.Desired.MaxPrice,
+			Quantity:       change.Desired.Quantity,
+		})
+		return err
+	case BuyOrderUpdate:
+		if err := api.awaitSuggestedWait(ctx, RatelimitKeyUpdateBuyOrder); err != nil {
+			return err
+		}
+		_, err := api.UpdateBuyOrderContext(ctx, change.Current.ID, UpdateBuyOrderPayload{
+			MaxPrice: change.Desired.MaxPrice,
+			Quantity: change.Desired.Quantity,
+		})
+		return err
+	default:
+		if err := api.awaitSuggestedWait(ctx, RatelimitKeyDeleteBuyOrder); err != nil {
+			return err
+		}
+		_, err := api.DeleteBuyOrderContext(ctx, change.Current.ID)
+		if errors.Is(err, ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+}
@@ -0,0 +1,89 @@
+package csfloat_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	csfloat "github.com/Bios-Marcel/csfloat_go"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_LoadDesiredBuyOrders(t *testing.T) {
+	ass := assert.New(t)
+
+	desired, err := csfloat.LoadDesiredBuyOrders(strings.NewReader(`[
+		{"market_hash_name": "AK-47 | Redline (Field-Tested)", "max_price": 900, "quantity": 1}
+	]`))
+	if ass.NoError(err) {
+		ass.Equal([]csfloat.DesiredBuyOrder{
+			{MarketHashName: "AK-47 | Redline (Field-Tested)", MaxPrice: 900, Quantity: 1},
+		}, desired)
+	}
+
+	_, err = csfloat.LoadDesiredBuyOrders(strings.NewReader(`[
+		{"market_hash_name": "AK-47 | Redline (Field-Tested)", "max_price": 900, "quantity": 1},
+		{"market_hash_name": "AK-47 | Redline (Field-Tested)", "max_price": 0, "quantity": 1}
+	]`))
+	ass.ErrorIs(err, csfloat.ErrInvalidDesiredBuyOrders)
+	ass.ErrorContains(err, "duplicate order")
+	ass.ErrorContains(err, "no max price")
+}
+
+func Test_PlanBuyOrders(t *testing.T) {
+	ass := assert.New(t)
+	server, api := newFakeServer(t)
+	server.AddMyBuyOrder(csfloat.ItemBuyOrder{ID: "1", MarketHashName: "AK-47 | Redline (Field-Tested)", Price: 900, Quantity: 1})
+	server.AddMyBuyOrder(csfloat.ItemBuyOrder{ID: "2", MarketHashName: "AWP | Asiimov (Field-Tested)", Price: 5000, Quantity: 1})
+	server.AddMyBuyOrder(csfloat.ItemBuyOrder{ID: "3", MarketHashName: "AWP | Asiimov (Field-Tested)", Price: 5000, Quantity: 1})
+	server.AddMyBuyOrder(csfloat.ItemBuyOrder{ID: "4", MarketHashName: "Glock-18 | Fade (Factory New)", Price: 100, Quantity: 3})
+	server.AddMyBuyOrder(csfloat.ItemBuyOrder{ID: "5", Expression: "DefIndex == 7", Price: 100, Quantity: 1})
+	server.AddBuyOrder(csfloat.ItemBuyOrder{ID: "6", MarketHashName: "M4A4 | Howl (Minimal Wear)", Price: 100, Quantity: 1})
+
+	desired := []csfloat.DesiredBuyOrder{
+		{MarketHashName: "AK-47 | Redline (Field-Tested)", MaxPrice: 950, Quantity: 1},
+		{MarketHashName: "AWP | Asiimov (Field-Tested)", MaxPrice: 5000, Quantity: 1},
+		{MarketHashName: "M4A4 | Howl (Minimal Wear)", MaxPrice: 200, Quantity: 2},
+	}
+	plan, err := api.PlanBuyOrders(context.Background(), desired)
+	if !ass.NoError(err) {
+		return
+	}
+	ass.Equal(`- AWP | Asiimov (Field-Tested): price 5000, quantity 1
+- Glock-18 | Fade (Factory New): price 100, quantity 3
+~ AK-47 | Redline (Field-Tested): price 900 -> 950, quantity 1
++ M4A4 | Howl (Minimal Wear): price 200, quantity 2
+
+Plan: 1 to create, 1 to update, 2 to delete.
+`, plan.String())
+	// Planning is a dry run.
+	ass.Len(server.BuyOrders(), 6)
+
+	applied, err := api.ApplyBuyOrderPlan(context.Background(), plan)
+	ass.NoError(err)
+	ass.Len(applied, 4)
+
+	plan, err = api.PlanBuyOrders(context.Background(), desired)
+	if ass.NoError(err) {
+		ass.Equal("No changes.\n", plan.String())
+	}
+	// The advanced order and the order of another user are untouched.
+	ass.Len(server.BuyOrders(), 5)
+}
+
+func Test_ApplyBuyOrderPlanAlreadyDeleted(t *testing.T) {
+	ass := assert.New(t)
+	server, api := newFakeServer(t)
+	server.AddMyBuyOrder(csfloat.ItemBuyOrder{ID: "1", MarketHashName: "AK-47 | Redline (Field-Tested)", Price: 900, Quantity: 1})
+
+	plan, err := api.PlanBuyOrders(context.Background(), nil)
+	if !ass.NoError(err) || !ass.Len(plan.Changes, 1) {
+		return
+	}
+	_, err = api.BulkDeleteBuyOrders("1")
+	ass.NoError(err)
+
+	applied, err := api.ApplyBuyOrderPlan(context.Background(), plan)
+	ass.NoError(err)
+	ass.Len(applied, 1)
+}
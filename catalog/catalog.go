@@ -0,0 +1,213 @@
+// Package catalog maps the indexes used by CSFloat, such as def_index and
+// paint_index, to names and item metadata, and back.
+//
+// The embedded catalog.json is generated from the game's item schema by
+// go generate, see internal/gen, and stamped with the game's patch version.
+// The checked in file still predates the generator and is partial. It
+// contains the weapons, knives and gloves, but only a selection of paints and
+// skins, and no stickers or charms, until it is regenerated. A complete
+// catalog can also be loaded via Parse.
+package catalog
+
+//go:generate go run ./internal/gen -o catalog.json
+
+import (
+	"bytes"
+	_ "embed"
+	json "encoding/json/v2"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+//go:embed catalog.json
+var embedded []byte
+
+// Weapon is a weapon, knife or glove type, identified by its def_index.
+type Weapon struct {
+	DefIndex uint   `json:"def_index"`
+	Name     string `json:"name"`
+	// Star is set for knives and gloves, whose market hash names start with
+	// "★".
+	Star bool `json:"star,omitzero"`
+}
+
+// Paint is a paint kit, identified by its paint_index. The same paint can be
+// applied to multiple weapons.
+type Paint struct {
+	PaintIndex uint   `json:"paint_index"`
+	Name       string `json:"name"`
+	// Phase is set for Doppler and Gamma Doppler, where each phase has its own
+	// paint index.
+	Phase string `json:"phase,omitzero"`
+	// MinFloat and MaxFloat limit the float of all items with this paint.
+	MinFloat float64 `json:"min_float"`
+	MaxFloat float64 `json:"max_float"`
+}
+
+// Skin is a paint applied to a specific weapon.
+type Skin struct {
+	DefIndex   uint   `json:"def_index"`
+	PaintIndex uint   `json:"paint_index"`
+	Collection string `json:"collection,omitzero"`
+	// Rarity uses the same values as csfloat.Rarity.
+	Rarity uint8 `json:"rarity,omitzero"`
+}
+
+type Sticker struct {
+	StickerIndex uint   `json:"sticker_index"`
+	Name         string `json:"name"`
+}
+
+type Charm struct {
+	CharmIndex uint   `json:"charm_index"`
+	Name       string `json:"name"`
+}
+
+// Catalog holds all known items. Use Default for the embedded catalog.
+type Catalog struct {
+	// Version is the game's patch version the catalog was generated from,
+	// such as "1.40.9.3".
+	Version  string    `json:"version"`
+	Weapons  []Weapon  `json:"weapons"`
+	Paints   []Paint   `json:"paints"`
+	Skins    []Skin    `json:"skins"`
+	Stickers []Sticker `json:"stickers"`
+	Charms   []Charm   `json:"charms"`
+
+	weapons        map[uint]Weapon
+	weaponsByName  map[string]Weapon
+	paints         map[uint]Paint
+	paintsByName   map[string][]Paint
+	skins          map[[2]uint]Skin
+	stickers       map[uint]Sticker
+	stickersByName map[string]Sticker
+	charms         map[uint]Charm
+	charmsByName   map[string]Charm
+}
+
+// Default returns the embedded catalog.
+var Default = sync.OnceValue(func() *Catalog {
+	catalog, err := Parse(bytes.NewReader(embedded))
+	if err != nil {
+		panic(err)
+	}
+	return catalog
+})
+
+// Parse reads a catalog in the format of the embedded catalog.json.
+func Parse(reader io.Reader) (*Catalog, error) {
+	var catalog Catalog
+	if err := json.UnmarshalRead(reader, &catalog); err != nil {
+		return nil, fmt.Errorf("error decoding catalog: %w", err)
+	}
+
+	catalog.weapons = make(map[uint]Weapon, len(catalog.Weapons))
+	catalog.weaponsByName = make(map[string]Weapon, len(catalog.Weapons))
+	for _, weapon := range catalog.Weapons {
+		catalog.weapons[weapon.DefIndex] = weapon
+		catalog.weaponsByName[key(weapon.Name)] = weapon
+	}
+	catalog.paints = make(map[uint]Paint, len(catalog.Paints))
+	catalog.paintsByName = make(map[string][]Paint, len(catalog.Paints))
+	for _, paint := range catalog.Paints {
+		catalog.paints[paint.PaintIndex] = paint
+		catalog.paintsByName[key(paint.Name)] = append(catalog.paintsByName[key(paint.Name)], paint)
+	}
+	catalog.skins = make(map[[2]uint]Skin, len(catalog.Skins))
+	for _, skin := range catalog.Skins {
+		catalog.skins[[2]uint{skin.DefIndex, skin.PaintIndex}] = skin
+	}
+	catalog.stickers = make(map[uint]Sticker, len(catalog.Stickers))
+	catalog.stickersByName = make(map[string]Sticker, len(catalog.Stickers))
+	for _, sticker := range catalog.Stickers {
+		catalog.stickers[sticker.StickerIndex] = sticker
+		catalog.stickersByName[key(sticker.Name)] = sticker
+	}
+	catalog.charms = make(map[uint]Charm, len(catalog.Charms))
+	catalog.charmsByName = make(map[string]Charm, len(catalog.Charms))
+	for _, charm := range catalog.Charms {
+		catalog.charms[charm.CharmIndex] = charm
+		catalog.charmsByName[key(charm.Name)] = charm
+	}
+
+	return &catalog, nil
+}
+
+// key normalizes names, so lookups by name are case insensitive.
+func key(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+func (catalog *Catalog) Weapon(defIndex uint) (Weapon, bool) {
+	weapon, ok := catalog.weapons[defIndex]
+	return weapon, ok
+}
+
+// WeaponByName looks up a weapon by its name, such as "AK-47" or "Karambit",
+// ignoring case.
+func (catalog *Catalog) WeaponByName(name string) (Weapon, bool) {
+	weapon, ok := catalog.weaponsByName[key(name)]
+	return weapon, ok
+}
+
+func (catalog *Catalog) Paint(paintIndex uint) (Paint, bool) {
+	paint, ok := catalog.paints[paintIndex]
+	return paint, ok
+}
+
+// PaintsByName returns all paints with the given name, ignoring case. There
+// can be multiple, for example one per Doppler phase, or paints that look
+// differently on different weapons, such as "Asiimov".
+func (catalog *Catalog) PaintsByName(name string) []Paint {
+	return catalog.paintsByName[key(name)]
+}
+
+// Skin returns the skin of the given weapon and paint. Only skins with known
+// collection or rarity are included.
+func (catalog *Catalog) Skin(defIndex, paintIndex uint) (Skin, bool) {
+	skin, ok := catalog.skins[[2]uint{defIndex, paintIndex}]
+	return skin, ok
+}
+
+// SkinByName returns the def and paint index of the given weapon and paint
+// name, for example "AK-47" and "Redline". If there are multiple paints with
+// that name, the one with a Skin entry for the weapon is preferred. The
+// collection and rarity are only set if known.
+func (catalog *Catalog) SkinByName(weapon, paint string) (Skin, bool) {
+	found, ok := catalog.WeaponByName(weapon)
+	if !ok {
+		return Skin{}, false
+	}
+	paints := catalog.PaintsByName(paint)
+	if len(paints) == 0 {
+		return Skin{}, false
+	}
+	for _, candidate := range paints {
+		if skin, ok := catalog.Skin(found.DefIndex, candidate.PaintIndex); ok {
+			return skin, true
+		}
+	}
+	return Skin{DefIndex: found.DefIndex, PaintIndex: paints[0].PaintIndex}, true
+}
+
+func (catalog *Catalog) Sticker(stickerIndex uint) (Sticker, bool) {
+	sticker, ok := catalog.stickers[stickerIndex]
+	return sticker, ok
+}
+
+func (catalog *Catalog) StickerByName(name string) (Sticker, bool) {
+	sticker, ok := catalog.stickersByName[key(name)]
+	return sticker, ok
+}
+
+func (catalog *Catalog) Charm(charmIndex uint) (Charm, bool) {
+	charm, ok := catalog.charms[charmIndex]
+	return charm, ok
+}
+
+func (catalog *Catalog) CharmByName(name string) (Charm, bool) {
+	charm, ok := catalog.charmsByName[key(name)]
+	return charm, ok
+}
@@ -0,0 +1,75 @@
+package catalog_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Bios-Marcel/csfloat_go/catalog"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Default(t *testing.T) {
+	ass := assert.New(t)
+	defaultCatalog := catalog.Default()
+	ass.NotEmpty(defaultCatalog.Version)
+
+	weapon, ok := defaultCatalog.Weapon(7)
+	if ass.True(ok) {
+		ass.Equal("AK-47", weapon.Name)
+		ass.False(weapon.Star)
+	}
+	weapon, ok = defaultCatalog.WeaponByName("karambit")
+	if ass.True(ok) {
+		ass.Equal(uint(507), weapon.DefIndex)
+		ass.True(weapon.Star)
+	}
+
+	paint, ok := defaultCatalog.Paint(282)
+	if ass.True(ok) {
+		ass.Equal("Redline", paint.Name)
+		ass.Equal(0.1, paint.MinFloat)
+		ass.Equal(0.7, paint.MaxFloat)
+	}
+	ass.Len(defaultCatalog.PaintsByName("Doppler"), 7)
+
+	skin, ok := defaultCatalog.SkinByName("AWP", "Asiimov")
+	if ass.True(ok) {
+		ass.Equal(uint(279), skin.PaintIndex)
+		ass.Equal("The Phoenix Collection", skin.Collection)
+	}
+	skin, ok = defaultCatalog.SkinByName("Karambit", "Fade")
+	if ass.True(ok) {
+		ass.Equal(uint(507), skin.DefIndex)
+		ass.Equal(uint(38), skin.PaintIndex)
+	}
+	_, ok = defaultCatalog.SkinByName("AK-47", "Unknown")
+	ass.False(ok)
+}
+
+func Test_DefaultConsistent(t *testing.T) {
+	defaultCatalog := catalog.Default()
+	for _, skin := range defaultCatalog.Skins {
+		_, ok := defaultCatalog.Weapon(skin.DefIndex)
+		assert.True(t, ok, "unknown weapon %d", skin.DefIndex)
+		_, ok = defaultCatalog.Paint(skin.PaintIndex)
+		assert.True(t, ok, "unknown paint %d", skin.PaintIndex)
+	}
+	for _, paint := range defaultCatalog.Paints {
+		assert.Less(t, paint.MinFloat, paint.MaxFloat, paint.Name)
+	}
+}
+
+func Test_Parse(t *testing.T) {
+	parsed, err := catalog.Parse(strings.NewReader(`{
+		"version": "test",
+		"stickers": [{"sticker_index": 1, "name": "Test Sticker"}]
+	}`))
+	if assert.NoError(t, err) {
+		sticker, ok := parsed.StickerByName("test sticker")
+		assert.True(t, ok)
+		assert.Equal(t, uint(1), sticker.StickerIndex)
+	}
+
+	_, err = catalog.Parse(strings.NewReader(`{`))
+	assert.Error(t, err)
+}
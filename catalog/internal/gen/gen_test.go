@@ -0,0 +1,148 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Bios-Marcel/csfloat_go/catalog"
+	"github.com/stretchr/testify/assert"
+)
+
+const testItemsGame = `"items_game"
+{
+	"prefabs"
+	{
+		"primary" { "item_class" "weapon_base" }
+		"rifle" { "prefab" "primary" }
+		"weapon_ak47_prefab"
+		{
+			"prefab"		"rifle"
+			"item_name"		"#SFUI_WPNHUD_AK47"
+		}
+		"melee_unusual" { }
+	}
+	"items"
+	{
+		"default" { "name" "default" }
+		"7" { "name" "weapon_ak47" "prefab" "weapon_ak47_prefab" }
+		"49" { "name" "weapon_c4" "item_name" "#SFUI_WPNHUD_C4" }
+		"507"
+		{
+			"name"		"weapon_knife_karambit"
+			"prefab"	"melee_unusual"
+			"item_name"	"#SFUI_WPNHUD_KnifeKaram" [$WIN32]
+		}
+	}
+	"paint_kits"
+	{
+		"0" { "name" "default" "wear_remap_min" "0.06" "wear_remap_max" "0.8" }
+		"282"
+		{
+			// Comments are ignored.
+			"name"				"cu_ak47_redline"
+			"description_tag"	"#PaintKit_cu_ak47_redline_Tag"
+			"wear_remap_min"	"0.1"
+			"wear_remap_max"	"0.7"
+		}
+		"418" { "name" "am_doppler_phase1" "description_tag" "#PaintKit_am_doppler_phase1_Tag" "wear_remap_max" "0.08" }
+	}
+	"paint_kits_rarity"
+	{
+		"am_doppler_phase1" "ancient"
+	}
+	"item_sets"
+	{
+		"set_phoenix"
+		{
+			"name" "#CSGO_set_phoenix"
+			"items" { "[cu_ak47_redline]weapon_ak47" "1" }
+		}
+	}
+	"client_loot_lists"
+	{
+		"set_phoenix_legendary" { "[cu_ak47_redline]weapon_ak47" "1" }
+		"crate_unusual" { "[am_doppler_phase1]weapon_knife_karambit" "1" }
+	}
+	"sticker_kits"
+	{
+		"1" { "name" "dh_gs" "item_name" "#StickerKit_dh_gs" }
+		"4501" { "name" "patch_1" "item_name" "#PatchKit_1" }
+	}
+	"keychain_definitions"
+	{
+		"1" { "name" "kc_lil_ava" "loc_name" "#keychain_kc_lil_ava" }
+	}
+}
+`
+
+const testLanguage = `"lang"
+{
+	"Language" "English"
+	"Tokens"
+	{
+		"SFUI_WPNHUD_AK47" "AK-47"
+		"SFUI_WPNHUD_C4" "C4 Explosive"
+		"SFUI_WPNHUD_KnifeKaram" "Karambit"
+		"PaintKit_cu_ak47_redline_Tag" "Redline"
+		"PaintKit_am_doppler_phase1_Tag" "Doppler"
+		"CSGO_set_phoenix" "The Phoenix Collection"
+		"StickerKit_dh_gs" "Gold \"Web\""
+		"PatchKit_1" "Some Patch"
+		"keychain_kc_lil_ava" "Lil' Ava"
+	}
+}
+`
+
+func Test_Run(t *testing.T) {
+	ass := assert.New(t)
+	source := t.TempDir()
+	for path, content := range map[string]string{
+		itemsGamePath: testItemsGame,
+		languagePath:  "\xef\xbb\xbf" + testLanguage,
+		steamInfPath:  "ClientVersion=2000573\nPatchVersion=1.40.9.3\n",
+	} {
+		path = filepath.Join(source, filepath.FromSlash(path))
+		if !ass.NoError(os.MkdirAll(filepath.Dir(path), 0o755)) ||
+			!ass.NoError(os.WriteFile(path, []byte(content), 0o644)) {
+			return
+		}
+	}
+
+	output := filepath.Join(t.TempDir(), "catalog.json")
+	if !ass.NoError(run(source, output)) {
+		return
+	}
+	file, err := os.Open(output)
+	if !ass.NoError(err) {
+		return
+	}
+	defer file.Close()
+	generated, err := catalog.Parse(file)
+	if !ass.NoError(err) {
+		return
+	}
+
+	ass.Equal("1.40.9.3", generated.Version)
+	ass.Equal([]catalog.Weapon{
+		{DefIndex: 7, Name: "AK-47"},
+		{DefIndex: 507, Name: "Karambit", Star: true},
+	}, generated.Weapons)
+	ass.Equal([]catalog.Paint{
+		{PaintIndex: 282, Name: "Redline", MinFloat: 0.1, MaxFloat: 0.7},
+		{PaintIndex: 418, Name: "Doppler", Phase: "Phase 1", MinFloat: 0.06, MaxFloat: 0.08},
+	}, generated.Paints)
+	ass.Equal([]catalog.Skin{
+		{DefIndex: 7, PaintIndex: 282, Collection: "The Phoenix Collection", Rarity: 5},
+		{DefIndex: 507, PaintIndex: 418, Rarity: 6},
+	}, generated.Skins)
+	ass.Equal([]catalog.Sticker{{StickerIndex: 1, Name: `Gold "Web"`}}, generated.Stickers)
+	ass.Equal([]catalog.Charm{{CharmIndex: 1, Name: "Lil' Ava"}}, generated.Charms)
+}
+
+func Test_ParseKeyValuesErrors(t *testing.T) {
+	for _, input := range []string{`"a" {`, `"a" "b" }`, `"a"`, `"a" "unterminated`} {
+		_, err := parseKeyValues([]byte(input))
+		assert.Error(t, err, input)
+	}
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+)
+
+// node is a KeyValues entry, either holding a value or child entries. Keys
+// may repeat, so children are kept in order instead of a map.
+type node struct {
+	key      string
+	value    string
+	children []*node
+}
+
+// get returns the last child with the given key, ignoring case, or nil.
+func (n *node) get(key string) *node {
+	if n == nil {
+		return nil
+	}
+	for i := len(n.children) - 1; i >= 0; i-- {
+		if strings.EqualFold(n.children[i].key, key) {
+			return n.children[i]
+		}
+	}
+	return nil
+}
+
+// all returns all children with the given key, ignoring case. items_game.txt
+// for example has multiple "items" blocks.
+func (n *node) all(key string) []*node {
+	if n == nil {
+		return nil
+	}
+	var found []*node
+	for _, child := range n.children {
+		if strings.EqualFold(child.key, key) {
+			found = append(found, child)
+		}
+	}
+	return found
+}
+
+// str returns the value of the child with the given key, or "".
+func (n *node) str(key string) string {
+	if child := n.get(key); child != nil {
+		return child.value
+	}
+	return ""
+}
+
+// parseKeyValues parses Valve's KeyValues text format, as used by
+// items_game.txt and the localization files. The returned node holds the
+// top level entries as children.
+func parseKeyValues(data []byte) (*node, error) {
+	parser := &kvParser{data: decodeText(data), line: 1}
+	root := &node{}
+	if err := parser.parseChildren(root, false); err != nil {
+		return nil, fmt.Errorf("line %d: %w", parser.line, err)
+	}
+	return root, nil
+}
+
+// decodeText strips byte order marks and converts UTF-16, which the game
+// uses for some localization files, to UTF-8.
+func decodeText(data []byte) string {
+	if bytes.HasPrefix(data, []byte{0xFF, 0xFE}) {
+		data = data[2:]
+		units := make([]uint16, 0, len(data)/2)
+		for i := 0; i+1 < len(data); i += 2 {
+			units = append(units, uint16(data[i])|uint16(data[i+1])<<8)
+		}
+		return string(utf16.Decode(units))
+	}
+	return string(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+}
+
+type kvParser struct {
+	data string
+	pos  int
+	line int
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenString
+	tokenOpen
+	tokenClose
+	// tokenCondition is a platform condition such as [$WIN32], which we
+	// ignore.
+	tokenCondition
+)
+
+func (parser *kvParser) parseChildren(parent *node, nested bool) error {
+	for {
+		kind, key, err := parser.next()
+		if err != nil {
+			return err
+		}
+		switch kind {
+		case tokenEOF:
+			if nested {
+				return fmt.Errorf("unexpected end of file in %q", parent.key)
+			}
+			return nil
+		case tokenClose:
+			if !nested {
+				return fmt.Errorf("unexpected }")
+			}
+			return nil
+		case tokenCondition:
+			continue
+		case tokenOpen:
+			return fmt.Errorf("unexpected {")
+		}
+
+		kind, value, err := parser.next()
+		if err != nil {
+			return err
+		}
+		child := &node{key: key}
+		switch kind {
+		case tokenString:
+			child.value = value
+		case tokenOpen:
+			if err := parser.parseChildren(child, true); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("missing value for %q", key)
+		}
+		parent.children = append(parent.children, child)
+	}
+}
+
+func (parser *kvParser) next() (tokenKind, string, error) {
+	parser.skipSpaceAndComments()
+	if parser.pos >= len(parser.data) {
+		return tokenEOF, "", nil
+	}
+
+	switch char := parser.data[parser.pos]; char {
+	case '{':
+		parser.pos++
+		return tokenOpen, "", nil
+	case '}':
+		parser.pos++
+		return tokenClose, "", nil
+	case '"':
+		value, err := parser.quoted()
+		return tokenString, value, err
+	case '[':
+		end := strings.IndexByte(parser.data[parser.pos:], ']')
+		if end < 0 {
+			return tokenEOF, "", fmt.Errorf("unterminated condition")
+		}
+		parser.pos += end + 1
+		return tokenCondition, "", nil
+	}
+
+	start := parser.pos
+	for parser.pos < len(parser.data) && !strings.ContainsRune(" \t\r\n{}\"", rune(parser.data[parser.pos])) {
+		parser.pos++
+	}
+	return tokenString, parser.data[start:parser.pos], nil
+}
+
+func (parser *kvParser) quoted() (string, error) {
+	parser.pos++
+	var builder strings.Builder
+	for parser.pos < len(parser.data) {
+		char := parser.data[parser.pos]
+		switch char {
+		case '"':
+			parser.pos++
+			return builder.String(), nil
+		case '\\':
+			if parser.pos+1 < len(parser.data) {
+				parser.pos++
+				switch escaped := parser.data[parser.pos]; escaped {
+				case 'n':
+					builder.WriteByte('\n')
+				case 't':
+					builder.WriteByte('\t')
+				default:
+					builder.WriteByte(escaped)
+				}
+				parser.pos++
+				continue
+			}
+		case '\n':
+			parser.line++
+		}
+		builder.WriteByte(char)
+		parser.pos++
+	}
+	return "", fmt.Errorf("unterminated string")
+}
+
+func (parser *kvParser) skipSpaceAndComments() {
+	for parser.pos < len(parser.data) {
+		switch char := parser.data[parser.pos]; {
+		case char == '\n':
+			parser.line++
+			parser.pos++
+		case char == ' ' || char == '\t' || char == '\r':
+			parser.pos++
+		case strings.HasPrefix(parser.data[parser.pos:], "//"):
+			end := strings.IndexByte(parser.data[parser.pos:], '\n')
+			if end < 0 {
+				parser.pos = len(parser.data)
+			} else {
+				parser.pos += end
+			}
+		default:
+			return
+		}
+	}
+}
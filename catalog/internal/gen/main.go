@@ -0,0 +1,174 @@
+// Command gen generates catalog.json from the game's item schema. It is run
+// via go generate in the catalog package:
+//
+//	go generate ./catalog
+//
+// By default, the schema is downloaded from the SteamDatabase game tracking
+// repository. A local copy with the same layout can be passed via -source.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json/jsontext"
+	json "encoding/json/v2"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Bios-Marcel/csfloat_go/catalog"
+)
+
+const (
+	defaultSource = "https://raw.githubusercontent.com/SteamDatabase/GameTracking-CS2/master/game/csgo"
+
+	itemsGamePath = "pak01_dir/scripts/items/items_game.txt"
+	languagePath  = "pak01_dir/resource/csgo_english.txt"
+	steamInfPath  = "steam.inf"
+)
+
+func main() {
+	source := flag.String("source", defaultSource, "URL or directory containing the game files")
+	output := flag.String("o", "catalog.json", "output file")
+	flag.Parse()
+
+	if err := run(*source, *output); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(source, output string) error {
+	itemsGame, err := loadKeyValues(source, itemsGamePath)
+	if err != nil {
+		return err
+	}
+	language, err := loadKeyValues(source, languagePath)
+	if err != nil {
+		return err
+	}
+	steamInf, err := load(source, steamInfPath)
+	if err != nil {
+		return err
+	}
+	version, err := patchVersion(steamInf)
+	if err != nil {
+		return err
+	}
+
+	generated, err := build(itemsGame, language, version)
+	if err != nil {
+		return err
+	}
+	var buffer bytes.Buffer
+	if err := write(&buffer, generated); err != nil {
+		return err
+	}
+	return os.WriteFile(output, buffer.Bytes(), 0o644)
+}
+
+// load reads a file relative to source, which is either a URL or a
+// directory.
+func load(source, path string) ([]byte, error) {
+	if !strings.HasPrefix(source, "https://") && !strings.HasPrefix(source, "http://") {
+		data, err := os.ReadFile(filepath.Join(source, filepath.FromSlash(path)))
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %w", path, err)
+		}
+		return data, nil
+	}
+
+	response, err := http.Get(strings.TrimSuffix(source, "/") + "/" + path)
+	if err != nil {
+		return nil, fmt.Errorf("error downloading %s: %w", path, err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error downloading %s: %s", path, response.Status)
+	}
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error downloading %s: %w", path, err)
+	}
+	return data, nil
+}
+
+func loadKeyValues(source, path string) (*node, error) {
+	data, err := load(source, path)
+	if err != nil {
+		return nil, err
+	}
+	root, err := parseKeyValues(data)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", path, err)
+	}
+	return root, nil
+}
+
+// patchVersion returns the PatchVersion of steam.inf, such as "1.40.9.3",
+// which the catalog is stamped with.
+func patchVersion(steamInf []byte) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(steamInf))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+		if ok && strings.EqualFold(key, "PatchVersion") {
+			return strings.TrimSpace(value), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("no PatchVersion in %s", steamInfPath)
+}
+
+// write encodes the catalog with one entry per line, keeping diffs between
+// versions readable.
+func write(writer io.Writer, generated *catalog.Catalog) error {
+	version, err := json.Marshal(generated.Version)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(writer, "{\n  \"version\": %s,\n", version)
+	if err := writeSection(writer, "weapons", generated.Weapons, false); err != nil {
+		return err
+	}
+	if err := writeSection(writer, "paints", generated.Paints, false); err != nil {
+		return err
+	}
+	if err := writeSection(writer, "skins", generated.Skins, false); err != nil {
+		return err
+	}
+	if err := writeSection(writer, "stickers", generated.Stickers, false); err != nil {
+		return err
+	}
+	if err := writeSection(writer, "charms", generated.Charms, true); err != nil {
+		return err
+	}
+	_, err = io.WriteString(writer, "}\n")
+	return err
+}
+
+func writeSection[T any](writer io.Writer, name string, entries []T, last bool) error {
+	fmt.Fprintf(writer, "  %q: [", name)
+	for index, entry := range entries {
+		line, err := json.Marshal(entry, jsontext.SpaceAfterColon(true), jsontext.SpaceAfterComma(true))
+		if err != nil {
+			return fmt.Errorf("error encoding %s: %w", name, err)
+		}
+		separator := ","
+		if index == len(entries)-1 {
+			separator = "\n  "
+		}
+		fmt.Fprintf(writer, "\n    %s%s", line, separator)
+	}
+	separator := ","
+	if last {
+		separator = ""
+	}
+	_, err := fmt.Fprintf(writer, "]%s\n", separator)
+	return err
+}
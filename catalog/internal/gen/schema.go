@@ -0,0 +1,338 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Bios-Marcel/csfloat_go/catalog"
+)
+
+// rarities maps the rarity names of items_game.txt to csfloat.Rarity.
+var rarities = map[string]uint8{
+	"common":    1,
+	"uncommon":  2,
+	"rare":      3,
+	"mythical":  4,
+	"legendary": 5,
+	"ancient":   6,
+	"immortal":  7,
+}
+
+// dopplerPhases maps parts of the paint kit names of Doppler and Gamma
+// Doppler to their phase, as each phase is a paint kit of its own.
+var dopplerPhases = []struct{ part, phase string }{
+	{"phase1", "Phase 1"},
+	{"phase2", "Phase 2"},
+	{"phase3", "Phase 3"},
+	{"phase4", "Phase 4"},
+	{"ruby", "Ruby"},
+	{"sapphire", "Sapphire"},
+	{"blackpearl", "Black Pearl"},
+	{"emerald", "Emerald"},
+}
+
+// schema gives access to items_game.txt and its translations.
+type schema struct {
+	itemsGame *node
+	tokens    map[string]string
+	prefabs   map[string]*node
+}
+
+// build converts the parsed items_game.txt and csgo_english.txt into a
+// catalog.
+func build(itemsGame, language *node, version string) (*catalog.Catalog, error) {
+	root := itemsGame.get("items_game")
+	if root == nil {
+		return nil, fmt.Errorf("missing items_game")
+	}
+	tokens := language.get("lang").get("Tokens")
+	if tokens == nil {
+		return nil, fmt.Errorf("missing lang tokens")
+	}
+
+	schema := &schema{
+		itemsGame: root,
+		tokens:    make(map[string]string, len(tokens.children)),
+		prefabs:   make(map[string]*node),
+	}
+	for _, token := range tokens.children {
+		schema.tokens[strings.ToLower(token.key)] = token.value
+	}
+	for _, prefabs := range root.all("prefabs") {
+		for _, prefab := range prefabs.children {
+			schema.prefabs[prefab.key] = prefab
+		}
+	}
+
+	generated := &catalog.Catalog{Version: version}
+	paints, paintsByCodename := schema.paints()
+	generated.Paints = paints
+	skins, skinItems := schema.skins(paintsByCodename)
+	generated.Weapons, generated.Skins = schema.weapons(skins, skinItems)
+	generated.Stickers = schema.stickers()
+	generated.Charms = schema.charms()
+	return generated, nil
+}
+
+// translate resolves a localization token such as "#SFUI_WPNHUD_AK47".
+func (schema *schema) translate(token string) string {
+	return schema.tokens[strings.ToLower(strings.TrimPrefix(token, "#"))]
+}
+
+// indexed calls fn for all children of all blocks with the given name, whose
+// key is a positive index.
+func (schema *schema) indexed(block string, fn func(index uint, entry *node)) {
+	for _, entries := range schema.itemsGame.all(block) {
+		for _, entry := range entries.children {
+			index, err := strconv.ParseUint(entry.key, 10, 0)
+			if err != nil || index == 0 {
+				continue
+			}
+			fn(uint(index), entry)
+		}
+	}
+}
+
+func (schema *schema) paints() ([]catalog.Paint, map[string]catalog.Paint) {
+	minFloat, maxFloat := 0.06, 0.8
+	for _, paintKits := range schema.itemsGame.all("paint_kits") {
+		if defaults := paintKits.get("0"); defaults != nil {
+			minFloat = parseFloat(defaults.str("wear_remap_min"), minFloat)
+			maxFloat = parseFloat(defaults.str("wear_remap_max"), maxFloat)
+		}
+	}
+
+	var paints []catalog.Paint
+	byCodename := make(map[string]catalog.Paint)
+	schema.indexed("paint_kits", func(index uint, kit *node) {
+		name := schema.translate(kit.str("description_tag"))
+		if name == "" {
+			return
+		}
+		paint := catalog.Paint{
+			PaintIndex: index,
+			Name:       name,
+			MinFloat:   parseFloat(kit.str("wear_remap_min"), minFloat),
+			MaxFloat:   parseFloat(kit.str("wear_remap_max"), maxFloat),
+		}
+		if name == "Doppler" || name == "Gamma Doppler" {
+			codename := strings.ToLower(kit.str("name"))
+			for _, phase := range dopplerPhases {
+				if strings.Contains(codename, phase.part) {
+					paint.Phase = phase.phase
+					break
+				}
+			}
+		}
+		paints = append(paints, paint)
+		byCodename[strings.ToLower(kit.str("name"))] = paint
+	})
+	slices.SortFunc(paints, func(a, b catalog.Paint) int {
+		return cmp.Compare(a.PaintIndex, b.PaintIndex)
+	})
+	return paints, byCodename
+}
+
+// skinRef is a skin as referenced in item sets and loot lists, such as
+// "[cu_ak47_redline]weapon_ak47".
+type skinRef struct {
+	paintIndex uint
+	item       string
+}
+
+// skins collects all skins referenced by item sets, which define the
+// collection, and loot lists, whose names end in the rarity. It returns the
+// skins by reference and the item names referenced.
+func (schema *schema) skins(paints map[string]catalog.Paint) (map[skinRef]catalog.Skin, map[string]struct{}) {
+	skins := make(map[skinRef]catalog.Skin)
+	items := make(map[string]struct{})
+	add := func(reference string, update func(skin *catalog.Skin)) {
+		paintName, item, ok := strings.Cut(strings.TrimPrefix(reference, "["), "]")
+		if !ok || !strings.HasPrefix(reference, "[") {
+			return
+		}
+		paint, ok := paints[strings.ToLower(paintName)]
+		if !ok {
+			return
+		}
+		ref := skinRef{paintIndex: paint.PaintIndex, item: item}
+		skin := skins[ref]
+		skin.PaintIndex = paint.PaintIndex
+		update(&skin)
+		skins[ref] = skin
+		items[item] = struct{}{}
+	}
+
+	for _, sets := range schema.itemsGame.all("item_sets") {
+		for _, set := range sets.children {
+			setItems := set.get("items")
+			if setItems == nil {
+				continue
+			}
+			collection := schema.translate(set.str("name"))
+			for _, item := range setItems.children {
+				add(item.key, func(skin *catalog.Skin) {
+					if skin.Collection == "" {
+						skin.Collection = collection
+					}
+				})
+			}
+		}
+	}
+	for _, lists := range schema.itemsGame.all("client_loot_lists") {
+		for _, list := range lists.children {
+			suffix := list.key[strings.LastIndexByte(list.key, '_')+1:]
+			rarity := rarities[suffix]
+			for _, item := range list.children {
+				add(item.key, func(skin *catalog.Skin) {
+					if skin.Rarity == 0 {
+						skin.Rarity = rarity
+					}
+				})
+			}
+		}
+	}
+
+	// Skins not found in any rarity specific loot list, such as knives,
+	// fall back to the rarity of the paint kit.
+	paintRarities := make(map[string]uint8)
+	for _, block := range schema.itemsGame.all("paint_kits_rarity") {
+		for _, entry := range block.children {
+			paintRarities[strings.ToLower(entry.key)] = rarities[entry.value]
+		}
+	}
+	codenames := make(map[uint]string, len(paints))
+	for codename, paint := range paints {
+		codenames[paint.PaintIndex] = codename
+	}
+	for ref, skin := range skins {
+		if skin.Rarity == 0 {
+			skin.Rarity = paintRarities[codenames[ref.paintIndex]]
+			skins[ref] = skin
+		}
+	}
+	return skins, items
+}
+
+// weapons returns all weapons, knives and gloves that can carry a skin, as
+// well as the skins with their def index resolved.
+func (schema *schema) weapons(skins map[skinRef]catalog.Skin, skinItems map[string]struct{}) ([]catalog.Weapon, []catalog.Skin) {
+	var weapons []catalog.Weapon
+	defIndexes := make(map[string]uint)
+	schema.indexed("items", func(defIndex uint, item *node) {
+		prefabs := schema.prefabChain(item)
+		_, hasSkins := skinItems[item.str("name")]
+		star := prefabs["melee_unusual"] || prefabs["hands_paintable"]
+		if !hasSkins && !star && !prefabs["primary"] && !prefabs["secondary"] {
+			return
+		}
+		name := schema.translate(schema.resolve(item, "item_name"))
+		if name == "" {
+			return
+		}
+		weapons = append(weapons, catalog.Weapon{DefIndex: defIndex, Name: name, Star: star})
+		defIndexes[item.str("name")] = defIndex
+	})
+	slices.SortFunc(weapons, func(a, b catalog.Weapon) int {
+		return cmp.Compare(a.DefIndex, b.DefIndex)
+	})
+
+	resolved := make([]catalog.Skin, 0, len(skins))
+	for ref, skin := range skins {
+		defIndex, ok := defIndexes[ref.item]
+		if !ok || (skin.Collection == "" && skin.Rarity == 0) {
+			continue
+		}
+		skin.DefIndex = defIndex
+		resolved = append(resolved, skin)
+	}
+	slices.SortFunc(resolved, func(a, b catalog.Skin) int {
+		return cmp.Or(cmp.Compare(a.DefIndex, b.DefIndex), cmp.Compare(a.PaintIndex, b.PaintIndex))
+	})
+	return weapons, resolved
+}
+
+// prefabChain returns the names of all prefabs the entry inherits from.
+func (schema *schema) prefabChain(entry *node) map[string]bool {
+	chain := make(map[string]bool)
+	var walk func(entry *node)
+	walk = func(entry *node) {
+		for _, name := range strings.Fields(entry.str("prefab")) {
+			if chain[name] {
+				continue
+			}
+			chain[name] = true
+			if prefab, ok := schema.prefabs[name]; ok {
+				walk(prefab)
+			}
+		}
+	}
+	walk(entry)
+	return chain
+}
+
+// resolve returns the value of key of the entry or, if not set, of the first
+// prefab defining it.
+func (schema *schema) resolve(entry *node, key string) string {
+	seen := make(map[string]bool)
+	var walk func(entry *node) string
+	walk = func(entry *node) string {
+		if value := entry.str(key); value != "" {
+			return value
+		}
+		for _, name := range strings.Fields(entry.str("prefab")) {
+			prefab, ok := schema.prefabs[name]
+			if !ok || seen[name] {
+				continue
+			}
+			seen[name] = true
+			if value := walk(prefab); value != "" {
+				return value
+			}
+		}
+		return ""
+	}
+	return walk(entry)
+}
+
+func (schema *schema) stickers() []catalog.Sticker {
+	var stickers []catalog.Sticker
+	schema.indexed("sticker_kits", func(index uint, kit *node) {
+		// Patches and graffiti share the block with stickers.
+		token := kit.str("item_name")
+		if !strings.HasPrefix(strings.ToLower(token), "#stickerkit_") {
+			return
+		}
+		if name := schema.translate(token); name != "" {
+			stickers = append(stickers, catalog.Sticker{StickerIndex: index, Name: name})
+		}
+	})
+	slices.SortFunc(stickers, func(a, b catalog.Sticker) int {
+		return cmp.Compare(a.StickerIndex, b.StickerIndex)
+	})
+	return stickers
+}
+
+func (schema *schema) charms() []catalog.Charm {
+	var charms []catalog.Charm
+	schema.indexed("keychain_definitions", func(index uint, definition *node) {
+		if name := schema.translate(definition.str("loc_name")); name != "" {
+			charms = append(charms, catalog.Charm{CharmIndex: index, Name: name})
+		}
+	})
+	slices.SortFunc(charms, func(a, b catalog.Charm) int {
+		return cmp.Compare(a.CharmIndex, b.CharmIndex)
+	})
+	return charms
+}
+
+func parseFloat(value string, fallback float64) float64 {
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return fallback
+	}
+	return parsed
+}
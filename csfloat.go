@@ -1,13 +1,15 @@
 package csfloat
 
 import (
-	json "encoding/json/v2"
+	"context"
 	"errors"
 	"net"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -15,58 +17,84 @@ import (
 // its unachievable to reduce the fee, so this is fine for now.
 const Fee float64 = 2
 
-const (
-	ErrorCodeOverpricedRedquireKYC = 4
-	ErrorCodeAlreadySold           = 4
-	// ErrorCodeInvalidPurchaseState is thrown along HTTP status code 422. It
-	// is unclear when exactly, but it seems similar to AlreadySold. It might
-	// be unlisted.
-	ErrorCodeInvalidPurchaseState = 6
-	ErrorCodePriceChanged         = 15
-	// ErrorCodeSalesHistoryNotAvailable implies that the history for a certain
-	// item was disabled. This is done for cases for example.
-	ErrorCodeSalesHistoryNotAvailable = 200
-)
-
+// API is the client for all CSFloat endpoints. It is safe for concurrent use
+// by multiple goroutines.
 type API struct {
 	httpClient *http.Client
 	apiKey     string
-	ratelimits map[RatelimitBucketKey]*Ratelimits
+	baseURL    string
+	userAgent  string
+	headers    http.Header
+
+	// ratelimitsLock guards all ratelimit related fields.
+	ratelimitsLock       sync.RWMutex
+	ratelimits           map[RatelimitBucketKey]*Ratelimits
+	ratelimitSubscribers map[RatelimitBucketKey]map[*ratelimitSubscription]struct{}
+	ratelimitModes       map[RatelimitBucketKey]RatelimitMode
+	defaultRatelimitMode RatelimitMode
+	ratelimitPacing      map[RatelimitBucketKey]*ratelimitPacing
+	ipWindows            map[AddressFamily]*ipWindow
+
+	retryPolicy     atomic.Pointer[RetryPolicy]
+	reconcilePolicy atomic.Pointer[ReconcilePolicy]
+
+	unknownErrorCodes unknownErrorCodes
 }
 
-func NewWithHTTPClient(apiKey string, client *http.Client) *API {
+// NewWithHTTPClient creates an API using the given client as is. Options
+// affecting the client, such as WithTimeout or WithTransport, are ignored.
+func NewWithHTTPClient(apiKey string, client *http.Client, opts ...Option) *API {
+	options := newOptions(opts)
 	return &API{
-		httpClient: client,
-		apiKey:     apiKey,
-		ratelimits: make(map[RatelimitBucketKey]*Ratelimits),
+		httpClient:           client,
+		apiKey:               apiKey,
+		baseURL:              options.baseURL,
+		userAgent:            options.userAgent,
+		headers:              options.headers,
+		ratelimits:           make(map[RatelimitBucketKey]*Ratelimits),
+		ratelimitSubscribers: make(map[RatelimitBucketKey]map[*ratelimitSubscription]struct{}),
+		ratelimitModes:       make(map[RatelimitBucketKey]RatelimitMode),
+		ratelimitPacing:      make(map[RatelimitBucketKey]*ratelimitPacing),
+		ipWindows:            make(map[AddressFamily]*ipWindow),
 	}
 }
 
-func New(apiKey string) *API {
-	dialer := &net.Dialer{
-		Timeout:   15 * time.Second,
-		KeepAlive: 90 * time.Second,
-	}
-	transport := &http.Transport{
-		DialContext:           dialer.DialContext,
-		MaxIdleConns:          2,
-		IdleConnTimeout:       90 * time.Second,
-		TLSHandshakeTimeout:   3 * time.Second,
-		ResponseHeaderTimeout: 15 * time.Second,
-		ExpectContinueTimeout: 3 * time.Second,
+func New(apiKey string, opts ...Option) *API {
+	options := newOptions(opts)
+
+	transport := options.transport
+	if transport == nil {
+		dialer := options.dialer
+		if dialer == nil {
+			dialer = &net.Dialer{
+				Timeout:   15 * time.Second,
+				KeepAlive: 90 * time.Second,
+			}
+		}
+		transport = &http.Transport{
+			DialContext:           dialer.DialContext,
+			MaxIdleConns:          2,
+			IdleConnTimeout:       90 * time.Second,
+			TLSHandshakeTimeout:   3 * time.Second,
+			ResponseHeaderTimeout: 15 * time.Second,
+			ExpectContinueTimeout: 3 * time.Second,
+		}
 	}
 
 	client := &http.Client{
 		Transport: transport,
-		Timeout:   15 * time.Second,
+		Timeout:   options.timeout,
 	}
-	return NewWithHTTPClient(apiKey, client)
+	return NewWithHTTPClient(apiKey, client, opts...)
 }
 
 type Stall struct {
 	Items      []ActiveListing `json:"data"`
 	Count      int             `json:"total_count"`
 	TotalPrice uint            `json:"total_price"`
+	// Cursor can be passed via StallRequest.Cursor to get the next page. It
+	// is empty if there are no more pages.
+	Cursor string `json:"cursor"`
 }
 
 type ListingType string
@@ -153,10 +181,13 @@ type ActiveListing struct {
 	Private          bool          `json:"private,omitzero"`
 	MaxOfferDiscount uint          `json:"max_offer_discount,omitzero"`
 	Watchers         uint          `json:"watchers,omitzero"`
+	// AuctionDetails is only set for auctions.
+	AuctionDetails *AuctionDetails `json:"auction_details,omitempty"`
 }
 
+// URL returns the listing's page on the website, see ParseItemURL.
 func (al *ActiveListing) URL() string {
-	return "https://csfloat.com/item/" + al.ID
+	return ItemURL(al.ID)
 }
 
 type InventoryItem struct {
@@ -167,7 +198,9 @@ type InventoryItem struct {
 }
 
 type Sticker struct {
-	Index     uint      `json:"stickerId"`
+	Index uint `json:"stickerId"`
+	// Slot is the position of the sticker, from 0 to 4.
+	Slot      uint      `json:"slot"`
 	Name      string    `json:"name"`
 	Reference Reference `json:"reference,omitzero"`
 	IconURL   string    `json:"icon_url"`
@@ -261,10 +294,11 @@ func (item *Item) ScreenshotURL(playside bool) string {
 // Category will map to the query category matching this item. This is required
 // for listing similar items.
 func (item *Item) Category() Category {
-	if strings.HasPrefix(item.MarketHashName, "Souvenir") {
+	name, _ := ParseMarketHashName(item.MarketHashName)
+	if name.Souvenir {
 		return Souvenir
 	}
-	if strings.HasPrefix(item.MarketHashName, "StatTrak") {
+	if name.StatTrak {
 		return StatTrak
 	}
 	return Normal
@@ -308,6 +342,41 @@ type ListingsRequest struct {
 	CharmIndex         uint
 	CharmHighlightReel uint
 	Type               ListingType
+	// Limit is the maximum amount of listings per page, at most 50. Defaults
+	// to 40.
+	Limit uint
+	// Cursor continues a previous search, see ListingsResponse.Cursor.
+	Cursor string
+
+	// MarketHashName can be built via MarketHashName.String.
+	MarketHashName string
+	Rarity         Rarity
+	// Collection is the collection's ID, such as "set_community_1", not its
+	// name.
+	Collection string
+	// Stickers filters for items with all of the given stickers applied.
+	Stickers []StickerFilter
+	// MaxRefQuantity limits the amount of reference listings, together with
+	// MinRefQuantity.
+	MaxRefQuantity uint
+	// MinFade and MaxFade filter by fade percentage, from 0 to 100.
+	MinFade float64
+	MaxFade float64
+	// These filter by blue gem percentages, from 0 to 100, see BlueGem.
+	MinPlaysideBlue float64
+	MaxPlaysideBlue float64
+	MinBacksideBlue float64
+	MaxBacksideBlue float64
+	// SellerID only returns listings of the seller with the given steam ID.
+	SellerID string
+}
+
+// StickerFilter matches items with the given sticker applied.
+type StickerFilter struct {
+	Index uint
+	// Slot restricts the position of the sticker, from 0 to 4. If nil, any
+	// position matches.
+	Slot *uint
 }
 
 type ListingResponse struct {
@@ -321,12 +390,18 @@ func (response *ListingResponse) responseBody() any {
 
 // Listing returns an existing listing.
 func (api *API) Listing(listingId string) (*ListingResponse, error) {
+	return api.ListingContext(context.Background(), listingId)
+}
+
+// ListingContext is like Listing, but uses ctx for the request.
+func (api *API) ListingContext(ctx context.Context, listingId string) (*ListingResponse, error) {
 	return handleRequest(
+		ctx,
 		api,
 		RatelimitKeyGetListing,
 		api.httpClient,
 		http.MethodGet,
-		"https://csfloat.com/api/v1/listings/"+listingId,
+		"/listings/"+listingId,
 		api.apiKey,
 		nil,
 		nil,
@@ -343,18 +418,61 @@ func (response *StallResponse) responseBody() any {
 	return &response.Stall
 }
 
+// Stall returns the first 40 listings of the given user's stall. Use
+// StallPage for more control, or AllStall to get the complete stall.
 func (api *API) Stall(steamId string) (*StallResponse, error) {
+	return api.StallContext(context.Background(), steamId)
+}
+
+// StallContext is like Stall, but uses ctx for the request.
+func (api *API) StallContext(ctx context.Context, steamId string) (*StallResponse, error) {
+	return api.StallPageContext(ctx, steamId, StallRequest{})
+}
+
+type StallRequest struct {
+	// Limit is the maximum amount of listings per page, at most 50. Defaults
+	// to 40.
+	Limit uint
+	// Cursor continues a previous request, see Stall.Cursor.
+	Cursor string
+	SortBy SortListingsBy
+	// Type filters by listing type. Empty means all types.
+	Type ListingType
+}
+
+// StallPage returns a single page of the given user's stall.
+func (api *API) StallPage(steamId string, request StallRequest) (*StallResponse, error) {
+	return api.StallPageContext(context.Background(), steamId, request)
+}
+
+// StallPageContext is like StallPage, but uses ctx for the request.
+func (api *API) StallPageContext(ctx context.Context, steamId string, request StallRequest) (*StallResponse, error) {
+	form := url.Values{}
+	if request.Limit > 0 {
+		form.Set("limit", strconv.FormatUint(uint64(request.Limit), 10))
+	} else {
+		form.Set("limit", "40")
+	}
+	if request.Cursor != "" {
+		form.Set("cursor", request.Cursor)
+	}
+	if request.SortBy != BestDeals {
+		form.Set("sort_by", string(request.SortBy))
+	}
+	if request.Type != "" {
+		form.Set("type", string(request.Type))
+	}
+
 	return handleRequest(
+		ctx,
 		api,
 		RatelimitKeyGetStall,
 		api.httpClient,
 		http.MethodGet,
-		"https://csfloat.com/api/v1/users/"+steamId+"/stall",
+		"/users/"+steamId+"/stall",
 		api.apiKey,
 		nil,
-		url.Values{
-			"limit": []string{"40"},
-		},
+		form,
 		&StallResponse{},
 	)
 }
@@ -368,21 +486,53 @@ func (response *InventoryResponse) responseBody() any {
 	return &response.Data
 }
 
-// Inventory returns all visible (tradable) items from the Steam inventory.
-// This includes items already listed in the stall, those will have a
-// `listing_id` set.
+// Inventory returns the first 40 visible (tradable) items from the Steam
+// inventory. This includes items already listed in the stall, those will have
+// a `listing_id` set. Use InventoryPage for more control, or AllInventory to
+// get the complete inventory.
 func (api *API) Inventory() (*InventoryResponse, error) {
+	return api.InventoryContext(context.Background())
+}
+
+// InventoryContext is like Inventory, but uses ctx for the request.
+func (api *API) InventoryContext(ctx context.Context) (*InventoryResponse, error) {
+	return api.InventoryPageContext(ctx, InventoryRequest{})
+}
+
+type InventoryRequest struct {
+	// Limit is the maximum amount of items per page. Defaults to 40.
+	Limit uint
+	// Offset is the amount of items to skip.
+	Offset uint
+}
+
+// InventoryPage returns a single page of the Steam inventory, see Inventory.
+func (api *API) InventoryPage(request InventoryRequest) (*InventoryResponse, error) {
+	return api.InventoryPageContext(context.Background(), request)
+}
+
+// InventoryPageContext is like InventoryPage, but uses ctx for the request.
+func (api *API) InventoryPageContext(ctx context.Context, request InventoryRequest) (*InventoryResponse, error) {
+	form := url.Values{}
+	if request.Limit > 0 {
+		form.Set("limit", strconv.FormatUint(uint64(request.Limit), 10))
+	} else {
+		form.Set("limit", "40")
+	}
+	if request.Offset > 0 {
+		form.Set("offset", strconv.FormatUint(uint64(request.Offset), 10))
+	}
+
 	return handleRequest(
+		ctx,
 		api,
 		RatelimitKeyGetInventory,
 		api.httpClient,
 		http.MethodGet,
-		"https://csfloat.com/api/v1/me/inventory",
+		"/me/inventory",
 		api.apiKey,
 		nil,
-		url.Values{
-			"limit": []string{"40"},
-		},
+		form,
 		&InventoryResponse{},
 	)
 }
@@ -403,12 +553,18 @@ func (response *MeResponse) responseBody() any {
 }
 
 func (api *API) Me() (*MeResponse, error) {
+	return api.MeContext(context.Background())
+}
+
+// MeContext is like Me, but uses ctx for the request.
+func (api *API) MeContext(ctx context.Context) (*MeResponse, error) {
 	return handleRequest(
+		ctx,
 		api,
 		RatelimitKeyGetMe,
 		api.httpClient,
 		http.MethodGet,
-		"https://csfloat.com/api/v1/me",
+		"/me",
 		api.apiKey,
 		nil,
 		nil,
@@ -424,13 +580,21 @@ type PostNewOfferRequest struct {
 	OfferId string `json:"offer_id"`
 }
 
+// PostNewOffer reports a Steam trade offer sent for a trade. For price offers
+// on listings, see SendOffer.
 func (api *API) PostNewOffer(offer PostNewOfferRequest) (*GenericResponse, error) {
+	return api.PostNewOfferContext(context.Background(), offer)
+}
+
+// PostNewOfferContext is like PostNewOffer, but uses ctx for the request.
+func (api *API) PostNewOfferContext(ctx context.Context, offer PostNewOfferRequest) (*GenericResponse, error) {
 	return handleRequest(
+		ctx,
 		api,
 		RatelimitKeyPostNewOffer,
 		api.httpClient,
 		http.MethodPost,
-		"https://csfloat.com/api/v1/trades/steam-status/new-offer",
+		"/trades/steam-status/new-offer",
 		api.apiKey,
 		offer,
 		nil,
@@ -448,12 +612,18 @@ func (response *AcceptTradesResponse) responseBody() any {
 }
 
 func (api *API) BulkAcceptTrade(tradeIds ...string) (*AcceptTradesResponse, error) {
+	return api.BulkAcceptTradeContext(context.Background(), tradeIds...)
+}
+
+// BulkAcceptTradeContext is like BulkAcceptTrade, but uses ctx for the request.
+func (api *API) BulkAcceptTradeContext(ctx context.Context, tradeIds ...string) (*AcceptTradesResponse, error) {
 	return handleRequest(
+		ctx,
 		api,
 		RatelimitKeyBulkAcceptTrade,
 		api.httpClient,
 		http.MethodPost,
-		"https://csfloat.com/api/v1/trades/bulk/accept",
+		"/trades/bulk/accept",
 		api.apiKey,
 		map[string]any{
 			"trade_ids": tradeIds,
@@ -464,12 +634,18 @@ func (api *API) BulkAcceptTrade(tradeIds ...string) (*AcceptTradesResponse, erro
 }
 
 func (api *API) BulkCancel(tradeIds ...string) (*GenericResponse, error) {
+	return api.BulkCancelContext(context.Background(), tradeIds...)
+}
+
+// BulkCancelContext is like BulkCancel, but uses ctx for the request.
+func (api *API) BulkCancelContext(ctx context.Context, tradeIds ...string) (*GenericResponse, error) {
 	return handleRequest(
+		ctx,
 		api,
 		RatelimitKeyBulkCancel,
 		api.httpClient,
 		http.MethodPost,
-		"https://csfloat.com/api/v1/trades/bulk/cancel",
+		"/trades/bulk/cancel",
 		api.apiKey,
 		map[string]any{
 			"trade_ids": tradeIds,
@@ -492,51 +668,86 @@ func (response *BulkListResponse) responseBody() any {
 	return response
 }
 
+// BulkList returns an *AmbiguousError if the request failed without a
+// definitive answer, see AmbiguousError.
 func (api *API) BulkList(items ...ListRequest) (*BulkListResponse, error) {
-	return handleRequest(
+	return api.BulkListContext(context.Background(), items...)
+}
+
+// BulkListContext is like BulkList, but uses ctx for the request.
+func (api *API) BulkListContext(ctx context.Context, items ...ListRequest) (*BulkListResponse, error) {
+	response, err := handleRequest(
+		ctx,
 		api,
 		RatelimitKeyBulkList,
 		api.httpClient,
 		http.MethodPost,
-		"https://csfloat.com/api/v1/listings/bulk-list",
+		"/listings/bulk-list",
 		api.apiKey,
 		BulkListRequest{Items: items},
 		nil,
 		&BulkListResponse{},
 	)
+	if isAmbiguous(err, response.Attempts) {
+		return response, api.reconcile(ctx, err, func(ctx context.Context) (Outcome, error) {
+			listed, outcome, err := api.reconcileList(ctx, items)
+			response.Data = listed
+			return outcome, err
+		})
+	}
+	return response, err
 }
 
+// BulkUnlist returns an *AmbiguousError if the request failed without a
+// definitive answer, see AmbiguousError.
 func (api *API) BulkUnlist(listingId ...string) (*GenericResponse, error) {
+	return api.BulkUnlistContext(context.Background(), listingId...)
+}
+
+// BulkUnlistContext is like BulkUnlist, but uses ctx for the request.
+func (api *API) BulkUnlistContext(ctx context.Context, listingId ...string) (*GenericResponse, error) {
 	if len(listingId) == 0 {
 		return nil, errors.New("no listings supplied")
 	}
+
+	var response *GenericResponse
+	var err error
 	if len(listingId) == 1 {
-		return handleRequest(
+		response, err = handleRequest(
+			ctx,
 			api,
 			RatelimitKeyUnlist,
 			api.httpClient,
 			http.MethodDelete,
-			"https://csfloat.com/api/v1/listings/"+listingId[0],
+			"/listings/"+listingId[0],
 			api.apiKey,
 			nil,
 			nil,
 			&GenericResponse{},
 		)
+	} else {
+		response, err = handleRequest(
+			ctx,
+			api,
+			RatelimitKeyBulkUnlist,
+			api.httpClient,
+			http.MethodPatch,
+			"/listings/bulk-delist",
+			api.apiKey,
+			map[string][]string{
+				"contract_ids": listingId,
+			},
+			nil,
+			&GenericResponse{},
+		)
 	}
 
-	return handleRequest(
-		api,
-		RatelimitKeyBulkUnlist,
-		api.httpClient,
-		http.MethodPatch,
-		"https://csfloat.com/api/v1/listings/bulk-delist",
-		api.apiKey,
-		map[string][]string{
-			"contract_ids": listingId,
-		},
-		nil,
-		&GenericResponse{},
-	)
+	if isAmbiguous(err, response.Attempts) {
+		return response, api.reconcile(ctx, err, func(ctx context.Context) (Outcome, error) {
+			return api.reconcileUnlist(ctx, listingId)
+		})
+	}
+	return response, err
 }
 
 type UnlistResponse struct {
@@ -548,12 +759,18 @@ func (response *UnlistResponse) responseBody() any {
 }
 
 func (api *API) Unlist(listingId string) (*UnlistResponse, error) {
+	return api.UnlistContext(context.Background(), listingId)
+}
+
+// UnlistContext is like Unlist, but uses ctx for the request.
+func (api *API) UnlistContext(ctx context.Context, listingId string) (*UnlistResponse, error) {
 	return handleRequest(
+		ctx,
 		api,
 		RatelimitKeyUnlist,
 		api.httpClient,
 		http.MethodDelete,
-		"https://csfloat.com/api/v1/listings/"+listingId,
+		"/listings/"+listingId,
 		api.apiKey,
 		nil,
 		nil,
@@ -566,19 +783,39 @@ type UpdateListingResponse struct {
 }
 
 func (api *API) UpdatePrivate(listingId string, private bool) (*UpdateListingResponse, error) {
-	return api.updateListing(listingId, map[string]any{"private": private})
+	return api.UpdatePrivateContext(context.Background(), listingId, private)
+}
+
+// UpdatePrivateContext is like UpdatePrivate, but uses ctx for the request.
+func (api *API) UpdatePrivateContext(ctx context.Context, listingId string, private bool) (*UpdateListingResponse, error) {
+	return api.updateListing(ctx, listingId, map[string]any{"private": private})
 }
 
 func (api *API) UpdateDescription(listingId string, description string) (*UpdateListingResponse, error) {
-	return api.updateListing(listingId, map[string]any{"description": description})
+	return api.UpdateDescriptionContext(context.Background(), listingId, description)
+}
+
+// UpdateDescriptionContext is like UpdateDescription, but uses ctx for the request.
+func (api *API) UpdateDescriptionContext(ctx context.Context, listingId string, description string) (*UpdateListingResponse, error) {
+	return api.updateListing(ctx, listingId, map[string]any{"description": description})
 }
 
 func (api *API) UpdateDiscount(listingId string, discount uint) (*UpdateListingResponse, error) {
-	return api.updateListing(listingId, map[string]any{"max_offer_discount": discount})
+	return api.UpdateDiscountContext(context.Background(), listingId, discount)
+}
+
+// UpdateDiscountContext is like UpdateDiscount, but uses ctx for the request.
+func (api *API) UpdateDiscountContext(ctx context.Context, listingId string, discount uint) (*UpdateListingResponse, error) {
+	return api.updateListing(ctx, listingId, map[string]any{"max_offer_discount": discount})
 }
 
 func (api *API) UpdatePrice(listingId string, price uint) (*UpdateListingResponse, error) {
-	return api.updateListing(listingId, map[string]any{"price": price})
+	return api.UpdatePriceContext(context.Background(), listingId, price)
+}
+
+// UpdatePriceContext is like UpdatePrice, but uses ctx for the request.
+func (api *API) UpdatePriceContext(ctx context.Context, listingId string, price uint) (*UpdateListingResponse, error) {
+	return api.updateListing(ctx, listingId, map[string]any{"price": price})
 }
 
 type UpdateListingRequest struct {
@@ -589,16 +826,22 @@ type UpdateListingRequest struct {
 }
 
 func (api *API) UpdateListing(id string, payload UpdateListingRequest) (*UpdateListingResponse, error) {
-	return api.updateListing(id, payload)
+	return api.UpdateListingContext(context.Background(), id, payload)
 }
 
-func (api *API) updateListing(listingId string, payload any) (*UpdateListingResponse, error) {
+// UpdateListingContext is like UpdateListing, but uses ctx for the request.
+func (api *API) UpdateListingContext(ctx context.Context, id string, payload UpdateListingRequest) (*UpdateListingResponse, error) {
+	return api.updateListing(ctx, id, payload)
+}
+
+func (api *API) updateListing(ctx context.Context, listingId string, payload any) (*UpdateListingResponse, error) {
 	return handleRequest(
+		ctx,
 		api,
 		RatelimitKeyUpdateListing,
 		api.httpClient,
 		http.MethodPatch,
-		"https://csfloat.com/api/v1/listings/"+listingId,
+		"/listings/"+listingId,
 		api.apiKey,
 		payload,
 		nil,
@@ -625,12 +868,6 @@ type ListRequest struct {
 	Private     bool        `json:"private,omitzero"`
 }
 
-type Error struct {
-	HttpStatus uint   `json:"-"`
-	Code       uint   `json:"code"`
-	Message    string `json:"message"`
-}
-
 type TradeState string
 
 const (
@@ -729,6 +966,11 @@ type TradesRequest struct {
 }
 
 func (api *API) Trades(payload TradesRequest) (*TradesResponse, error) {
+	return api.TradesContext(context.Background(), payload)
+}
+
+// TradesContext is like Trades, but uses ctx for the request.
+func (api *API) TradesContext(ctx context.Context, payload TradesRequest) (*TradesResponse, error) {
 	if payload.Limit == 0 {
 		payload.Limit = 100
 	}
@@ -750,11 +992,12 @@ func (api *API) Trades(payload TradesRequest) (*TradesResponse, error) {
 	form.Set("limit", strconv.FormatUint(uint64(payload.Limit), 10))
 
 	return handleRequest(
+		ctx,
 		api,
 		RatelimitKeyGetTrades,
 		api.httpClient,
 		http.MethodGet,
-		"https://csfloat.com/api/v1/me/trades",
+		"/me/trades",
 		api.apiKey,
 		nil,
 		form,
@@ -770,6 +1013,7 @@ type HistoryEntry struct {
 }
 
 type HistoryRequestPayload struct {
+	// MarketHashName can be built via MarketHashName.String.
 	MarketHashName string
 	PaintIndex     uint
 }
@@ -784,6 +1028,11 @@ func (response *HistoryResponse) responseBody() any {
 }
 
 func (api *API) History(payload HistoryRequestPayload) (*HistoryResponse, error) {
+	return api.HistoryContext(context.Background(), payload)
+}
+
+// HistoryContext is like History, but uses ctx for the request.
+func (api *API) HistoryContext(ctx context.Context, payload HistoryRequestPayload) (*HistoryResponse, error) {
 	form := url.Values{}
 
 	// Passing zero for a case / sticker will yield in an empty result.
@@ -793,11 +1042,12 @@ func (api *API) History(payload HistoryRequestPayload) (*HistoryResponse, error)
 	}
 
 	return handleRequest(
+		ctx,
 		api,
 		RatelimitKeyGetHistory,
 		api.httpClient,
 		http.MethodGet,
-		"https://csfloat.com/api/v1/history/"+url.QueryEscape(payload.MarketHashName)+"/sales",
+		"/history/"+url.QueryEscape(payload.MarketHashName)+"/sales",
 		api.apiKey,
 		nil,
 		form,
@@ -818,27 +1068,47 @@ type BuyRequestPayload struct {
 	TotalPrice  uint     `json:"total_price"`
 }
 
+// Buy returns an *AmbiguousError if the request failed without a
+// definitive answer, see AmbiguousError.
 func (api *API) Buy(payload BuyRequestPayload) (*BuyResponse, error) {
-	return handleRequest(
+	return api.BuyContext(context.Background(), payload)
+}
+
+// BuyContext is like Buy, but uses ctx for the request.
+func (api *API) BuyContext(ctx context.Context, payload BuyRequestPayload) (*BuyResponse, error) {
+	response, err := handleRequest(
+		ctx,
 		api,
 		RatelimitKeyBuy,
 		api.httpClient,
 		http.MethodPost,
-		"https://csfloat.com/api/v1/listings/buy",
+		"/listings/buy",
 		api.apiKey,
 		payload,
 		nil,
 		&BuyResponse{},
 	)
+	if isAmbiguous(err, response.Attempts) {
+		return response, api.reconcile(ctx, err, func(ctx context.Context) (Outcome, error) {
+			return api.reconcileBuy(ctx, payload, response.Attempts[0].StartedAt.Add(-tradeTimeSlack))
+		})
+	}
+	return response, err
 }
 
 func (api *API) Unwatch(listingId string) (*GenericResponse, error) {
+	return api.UnwatchContext(context.Background(), listingId)
+}
+
+// UnwatchContext is like Unwatch, but uses ctx for the request.
+func (api *API) UnwatchContext(ctx context.Context, listingId string) (*GenericResponse, error) {
 	return handleRequest(
+		ctx,
 		api,
 		RatelimitKeyUnwatch,
 		api.httpClient,
 		http.MethodDelete,
-		"https://csfloat.com/api/v1/listings/"+listingId+"/watchlist",
+		"/listings/"+listingId+"/watchlist",
 		api.apiKey,
 		nil,
 		nil,
@@ -847,12 +1117,18 @@ func (api *API) Unwatch(listingId string) (*GenericResponse, error) {
 }
 
 func (api *API) Watch(listingId string) (*GenericResponse, error) {
+	return api.WatchContext(context.Background(), listingId)
+}
+
+// WatchContext is like Watch, but uses ctx for the request.
+func (api *API) WatchContext(ctx context.Context, listingId string) (*GenericResponse, error) {
 	return handleRequest(
+		ctx,
 		api,
 		RatelimitKeyWatch,
 		api.httpClient,
 		http.MethodPost,
-		"https://csfloat.com/api/v1/listings/"+listingId+"/watchlist",
+		"/listings/"+listingId+"/watchlist",
 		api.apiKey,
 		nil,
 		nil,
@@ -883,13 +1159,19 @@ type ItemBuyOrder struct {
 	ID string `json:"id"`
 	// MarketHashName is only used for simple buy orders.
 	MarketHashName string `json:"market_hash_name"`
-	// Expression is only used for advanced buy orders.
+	// Expression is only used for advanced buy orders. It can be parsed and
+	// evaluated via the expression package.
 	Expression string `json:"expression,omitempty"`
 	Quantity   uint   `json:"qty,omitzero"`
 	Price      uint   `json:"price"`
 }
 
 func (api *API) ItemBuyOrders(item *Item) (*ItemBuyOrdersResponse, error) {
+	return api.ItemBuyOrdersContext(context.Background(), item)
+}
+
+// ItemBuyOrdersContext is like ItemBuyOrders, but uses ctx for the request.
+func (api *API) ItemBuyOrdersContext(ctx context.Context, item *Item) (*ItemBuyOrdersResponse, error) {
 	formValues := url.Values{"limit": []string{"3"}}
 
 	formValues.Set("url", item.SerializedInspect)
@@ -897,14 +1179,15 @@ func (api *API) ItemBuyOrders(item *Item) (*ItemBuyOrdersResponse, error) {
 	formValues.Set("market_hash_name", item.MarketHashName)
 
 	method := http.MethodGet
-	url := "https://csfloat.com/api/v1/buy-orders/item"
+	path := "/buy-orders/item"
 
 	return handleRequest(
+		ctx,
 		api,
 		RatelimitKeyGetItemBuyOrders,
 		api.httpClient,
 		method,
-		url,
+		path,
 		api.apiKey,
 		nil,
 		formValues,
@@ -913,6 +1196,11 @@ func (api *API) ItemBuyOrders(item *Item) (*ItemBuyOrdersResponse, error) {
 }
 
 func (api *API) SimpleItemBuyOrders(item *Item) (*SimpleItemBuyOrdersResponse, error) {
+	return api.SimpleItemBuyOrdersContext(context.Background(), item)
+}
+
+// SimpleItemBuyOrdersContext is like SimpleItemBuyOrders, but uses ctx for the request.
+func (api *API) SimpleItemBuyOrdersContext(ctx context.Context, item *Item) (*SimpleItemBuyOrdersResponse, error) {
 	formValues := url.Values{"limit": []string{"3"}}
 
 	body := map[string]string{
@@ -920,14 +1208,15 @@ func (api *API) SimpleItemBuyOrders(item *Item) (*SimpleItemBuyOrdersResponse, e
 	}
 	// Well ... this is being abused for providing a body it seems.
 	method := http.MethodPost
-	url := "https://csfloat.com/api/v1/buy-orders/similar-orders"
+	path := "/buy-orders/similar-orders"
 
 	return handleRequest(
+		ctx,
 		api,
 		RatelimitKeyGetSimpleItemBuyOrders,
 		api.httpClient,
 		method,
-		url,
+		path,
 		api.apiKey,
 		body,
 		formValues,
@@ -936,12 +1225,18 @@ func (api *API) SimpleItemBuyOrders(item *Item) (*SimpleItemBuyOrdersResponse, e
 }
 
 func (api *API) ListingBuyOrders(listingId string, limit int64) (*ItemBuyOrdersResponse, error) {
+	return api.ListingBuyOrdersContext(context.Background(), listingId, limit)
+}
+
+// ListingBuyOrdersContext is like ListingBuyOrders, but uses ctx for the request.
+func (api *API) ListingBuyOrdersContext(ctx context.Context, listingId string, limit int64) (*ItemBuyOrdersResponse, error) {
 	return handleRequest(
+		ctx,
 		api,
 		RatelimitKeyGetListingBuyOrders,
 		api.httpClient,
 		http.MethodGet,
-		"https://csfloat.com/api/v1/listings/"+listingId+"/buy-orders",
+		"/listings/"+listingId+"/buy-orders",
 		api.apiKey,
 		nil,
 		url.Values{"limit": []string{strconv.FormatInt(limit, 10)}},
@@ -959,12 +1254,18 @@ func (response *SimilarResponse) responseBody() any {
 }
 
 func (api *API) Similar(listingId string) (*SimilarResponse, error) {
+	return api.SimilarContext(context.Background(), listingId)
+}
+
+// SimilarContext is like Similar, but uses ctx for the request.
+func (api *API) SimilarContext(ctx context.Context, listingId string) (*SimilarResponse, error) {
 	return handleRequest(
+		ctx,
 		api,
 		RatelimitKeyGetSimilar,
 		api.httpClient,
 		http.MethodGet,
-		"https://csfloat.com/api/v1/listings/"+listingId+"/similar",
+		"/listings/"+listingId+"/similar",
 		api.apiKey,
 		nil,
 		nil,
@@ -972,12 +1273,6 @@ func (api *API) Similar(listingId string) (*SimilarResponse, error) {
 	)
 }
 
-func errorFrom(response *http.Response) (Error, error) {
-	var csfloatError Error
-	csfloatError.HttpStatus = uint(response.StatusCode)
-	return csfloatError, json.UnmarshalRead(response.Body, &csfloatError)
-}
-
 type TransactionType string
 
 const (
@@ -1092,6 +1387,11 @@ func (response *TransactionsResponse) responseBody() any {
 }
 
 func (api *API) Transactions(payload TransactionsRequest) (*TransactionsResponse, error) {
+	return api.TransactionsContext(context.Background(), payload)
+}
+
+// TransactionsContext is like Transactions, but uses ctx for the request.
+func (api *API) TransactionsContext(ctx context.Context, payload TransactionsRequest) (*TransactionsResponse, error) {
 	if payload.Limit == 0 {
 		payload.Limit = 100
 	}
@@ -1102,11 +1402,12 @@ func (api *API) Transactions(payload TransactionsRequest) (*TransactionsResponse
 	form.Set("limit", strconv.FormatUint(uint64(payload.Limit), 10))
 
 	return handleRequest(
+		ctx,
 		api,
 		RatelimitKeyGetTransactions,
 		api.httpClient,
 		http.MethodGet,
-		"https://csfloat.com/api/v1/me/transactions",
+		"/me/transactions",
 		api.apiKey,
 		nil,
 		form,
@@ -1123,93 +1424,78 @@ func (response *ListResponse) responseBody() any {
 	return &response.Item
 }
 
+// List returns an *AmbiguousError if the request failed without a
+// definitive answer, see AmbiguousError.
 func (api *API) List(payload ListRequest) (*ListResponse, error) {
-	return handleRequest(
+	return api.ListContext(context.Background(), payload)
+}
+
+// ListContext is like List, but uses ctx for the request.
+func (api *API) ListContext(ctx context.Context, payload ListRequest) (*ListResponse, error) {
+	response, err := handleRequest(
+		ctx,
 		api,
 		RatelimitKeyCreateListing,
 		api.httpClient,
 		http.MethodPost,
-		"https://csfloat.com/api/v1/listings",
+		"/listings",
 		api.apiKey,
 		payload,
 		nil,
 		&ListResponse{},
 	)
+	if isAmbiguous(err, response.Attempts) {
+		return response, api.reconcile(ctx, err, func(ctx context.Context) (Outcome, error) {
+			listed, outcome, err := api.reconcileList(ctx, []ListRequest{payload})
+			if len(listed) > 0 {
+				response.Item = listed[0]
+			}
+			return outcome, err
+		})
+	}
+	return response, err
 }
 
 type ListingsResponse struct {
 	GenericResponse
-	Data []*ActiveListing `json:"data"`
+	// Cursor can be passed via ListingsRequest.Cursor to get the next page.
+	// It is empty if there are no more pages.
+	Cursor string           `json:"cursor"`
+	Data   []*ActiveListing `json:"data"`
 }
 
 func (response *ListingsResponse) responseBody() any {
 	return response
 }
 
+// Listings searches the market. The query is validated first, so invalid
+// queries don't use up the ratelimit, see ListingsRequest.Validate.
 func (api *API) Listings(query ListingsRequest) (*ListingsResponse, error) {
-	form := url.Values{}
-	form.Set("limit", "40")
-	// Empty = BestDeals = Default
-	if query.SortBy != BestDeals {
-		form.Set("sort_by", string(query.SortBy))
-	}
-	if query.MinRefQuantity > 0 {
-		form.Set("min_ref_qty", strconv.FormatUint(uint64(query.MinRefQuantity), 10))
-	} else if query.ExcludeRare {
-		form.Set("min_ref_qty", strconv.FormatUint(20, 10))
-	}
-	if query.DefIndex > 0 {
-		form.Set("def_index", strconv.FormatUint(uint64(query.DefIndex), 10))
-	}
-	if query.PaintIndex > 0 {
-		form.Set("paint_index", strconv.FormatUint(uint64(query.PaintIndex), 10))
-	}
-	if len(query.PaintSeed) > 0 {
-		form.Set("paint_seed", concatInts(query.PaintSeed...))
-	}
-	if len(query.Categories) > 0 {
-		// Since mid-may it supports multi value
-		form.Set("category", concatInts(query.Categories...))
-	}
-	if query.MinPrice > 0 {
-		form.Set("min_price", strconv.Itoa(query.MinPrice))
-	}
-	if query.MaxPrice > 0 {
-		form.Set("max_price", strconv.Itoa(query.MaxPrice))
-	}
-	if query.MinFloat > 0 {
-		form.Set("min_float", strconv.FormatFloat(float64(query.MinFloat), 'f', -1, 32))
-	}
-	if query.MaxFloat > 0 {
-		form.Set("max_float", strconv.FormatFloat(float64(query.MaxFloat), 'f', -1, 32))
-	}
-	if query.StickerIndex > 0 {
-		form.Set("sticker_index", strconv.FormatUint(uint64(query.StickerIndex), 10))
-	}
-	if query.CharmIndex > 0 {
-		form.Set("keychain_index", strconv.FormatUint(uint64(query.CharmIndex), 10))
-	}
-	if query.CharmHighlightReel > 0 {
-		form.Set("keychain_highlight_reel", strconv.FormatUint(uint64(query.CharmHighlightReel), 10))
-	}
-	if query.Type != "" {
-		form.Set("type", string(query.Type))
+	return api.ListingsContext(context.Background(), query)
+}
+
+// ListingsContext is like Listings, but uses ctx for the request.
+func (api *API) ListingsContext(ctx context.Context, query ListingsRequest) (*ListingsResponse, error) {
+	if err := query.Validate(); err != nil {
+		return &ListingsResponse{}, err
 	}
 
 	return handleRequest(
+		ctx,
 		api,
 		RatelimitKeyGetListings,
 		api.httpClient,
 		http.MethodGet,
-		"https://csfloat.com/api/v1/listings",
+		"/listings",
 		api.apiKey,
 		nil,
-		form,
+		query.Values(),
 		&ListingsResponse{},
 	)
 }
 
 type CreateSimpleBuyOrderPayload struct {
+	// MarketHashName can be built via MarketHashName.String.
 	MarketHashName string `json:"market_hash_name"`
 	MaxPrice       uint   `json:"max_price"`
 	Quantity       uint   `json:"quantity"`
@@ -1225,12 +1511,18 @@ func (response *CreateSimpleBuyOrderResponse) responseBody() any {
 }
 
 func (api *API) CreateSimpleBuyOrder(payload CreateSimpleBuyOrderPayload) (*CreateSimpleBuyOrderResponse, error) {
+	return api.CreateSimpleBuyOrderContext(context.Background(), payload)
+}
+
+// CreateSimpleBuyOrderContext is like CreateSimpleBuyOrder, but uses ctx for the request.
+func (api *API) CreateSimpleBuyOrderContext(ctx context.Context, payload CreateSimpleBuyOrderPayload) (*CreateSimpleBuyOrderResponse, error) {
 	return handleRequest(
+		ctx,
 		api,
 		RatelimitKeyCreateBuyOrder,
 		api.httpClient,
 		http.MethodPost,
-		"https://csfloat.com/api/v1/buy-orders",
+		"/buy-orders",
 		api.apiKey,
 		payload,
 		nil,
@@ -1238,13 +1530,160 @@ func (api *API) CreateSimpleBuyOrder(payload CreateSimpleBuyOrderPayload) (*Crea
 	)
 }
 
+// CreateBuyOrderPayload creates an advanced buy order, matching all items that
+// satisfy the expression. Use CreateSimpleBuyOrderPayload for buy orders for a
+// market hash name.
+type CreateBuyOrderPayload struct {
+	// Expression can be built via the expression package.
+	Expression string `json:"expression"`
+	MaxPrice   uint   `json:"max_price"`
+	Quantity   uint   `json:"quantity"`
+}
+
+type BuyOrderResponse struct {
+	GenericResponse
+	ItemBuyOrder
+}
+
+func (response *BuyOrderResponse) responseBody() any {
+	return response
+}
+
+// CreateBuyOrder creates an advanced buy order.
+func (api *API) CreateBuyOrder(payload CreateBuyOrderPayload) (*BuyOrderResponse, error) {
+	return api.CreateBuyOrderContext(context.Background(), payload)
+}
+
+// CreateBuyOrderContext is like CreateBuyOrder, but uses ctx for the request.
+func (api *API) CreateBuyOrderContext(ctx context.Context, payload CreateBuyOrderPayload) (*BuyOrderResponse, error) {
+	return handleRequest(
+		ctx,
+		api,
+		RatelimitKeyCreateBuyOrder,
+		api.httpClient,
+		http.MethodPost,
+		"/buy-orders",
+		api.apiKey,
+		payload,
+		nil,
+		&BuyOrderResponse{},
+	)
+}
+
+type MyBuyOrdersRequest struct {
+	Page uint
+	// Limit defaults to 100.
+	Limit uint
+}
+
+type MyBuyOrdersResponse struct {
+	GenericResponse
+	Orders []ItemBuyOrder `json:"orders"`
+	Count  uint           `json:"count"`
+}
+
+func (response *MyBuyOrdersResponse) responseBody() any {
+	return response
+}
+
+// MyBuyOrders returns a page of our own simple and advanced buy orders, newest
+// first. Use AllMyBuyOrders to get all of them.
+func (api *API) MyBuyOrders(request MyBuyOrdersRequest) (*MyBuyOrdersResponse, error) {
+	return api.MyBuyOrdersContext(context.Background(), request)
+}
+
+// MyBuyOrdersContext is like MyBuyOrders, but uses ctx for the request.
+func (api *API) MyBuyOrdersContext(ctx context.Context, request MyBuyOrdersRequest) (*MyBuyOrdersResponse, error) {
+	if request.Limit == 0 {
+		request.Limit = 100
+	}
+	return handleRequest(
+		ctx,
+		api,
+		RatelimitKeyGetMyBuyOrders,
+		api.httpClient,
+		http.MethodGet,
+		"/me/buy-orders",
+		api.apiKey,
+		nil,
+		url.Values{
+			"page":  []string{strconv.FormatUint(uint64(request.Page), 10)},
+			"limit": []string{strconv.FormatUint(uint64(request.Limit), 10)},
+			"order": []string{"desc"},
+		},
+		&MyBuyOrdersResponse{},
+	)
+}
+
+// UpdateBuyOrderPayload changes an existing buy order in place. Zero values
+// are left unchanged.
+type UpdateBuyOrderPayload struct {
+	MaxPrice uint `json:"max_price,omitzero"`
+	Quantity uint `json:"quantity,omitzero"`
+}
+
+// UpdateBuyOrder changes the price and / or quantity of one of our buy orders.
+func (api *API) UpdateBuyOrder(id string, payload UpdateBuyOrderPayload) (*BuyOrderResponse, error) {
+	return api.UpdateBuyOrderContext(context.Background(), id, payload)
+}
+
+// UpdateBuyOrderContext is like UpdateBuyOrder, but uses ctx for the request.
+func (api *API) UpdateBuyOrderContext(ctx context.Context, id string, payload UpdateBuyOrderPayload) (*BuyOrderResponse, error) {
+	return handleRequest(
+		ctx,
+		api,
+		RatelimitKeyUpdateBuyOrder,
+		api.httpClient,
+		http.MethodPatch,
+		"/buy-orders/"+id,
+		api.apiKey,
+		payload,
+		nil,
+		&BuyOrderResponse{},
+	)
+}
+
+// BulkDeleteBuyOrders deletes all given buy orders with a single request.
+func (api *API) BulkDeleteBuyOrders(ids ...string) (*GenericResponse, error) {
+	return api.BulkDeleteBuyOrdersContext(context.Background(), ids...)
+}
+
+// BulkDeleteBuyOrdersContext is like BulkDeleteBuyOrders, but uses ctx for
+// the request.
+func (api *API) BulkDeleteBuyOrdersContext(ctx context.Context, ids ...string) (*GenericResponse, error) {
+	if len(ids) == 0 {
+		return nil, errors.New("no buy orders supplied")
+	}
+
+	return handleRequest(
+		ctx,
+		api,
+		RatelimitKeyBulkDeleteBuyOrders,
+		api.httpClient,
+		http.MethodPost,
+		"/buy-orders/bulk-delete",
+		api.apiKey,
+		map[string]any{
+			"ids": ids,
+		},
+		nil,
+		&GenericResponse{},
+	)
+}
+
 func (api *API) DeleteBuyOrder(id string) (*GenericResponse, error) {
+	return api.DeleteBuyOrderContext(context.Background(), id)
+}
+
+// DeleteBuyOrderContext is like DeleteBuyOrder, but uses ctx for the request.
+func (api *API) DeleteBuyOrderContext(ctx context.Context, id string) (*GenericResponse, error) {
 	return handleRequest(
+		ctx,
 		api,
 		RatelimitKeyDeleteBuyOrder,
 		api.httpClient,
 		http.MethodDelete,
-		"https://csfloat.com/api/v1/buy-orders/"+id,
+		"/buy-orders/"+id,
 		api.apiKey,
 		nil,
 		nil,
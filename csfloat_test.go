@@ -7,12 +7,13 @@ import (
 	"log"
 	"net"
 	"net/http"
-	"os"
-	"sync"
+	"net/http/httptest"
+	"strconv"
 	"testing"
 	"time"
 
 	csfloat "github.com/Bios-Marcel/csfloat_go"
+	"github.com/Bios-Marcel/csfloat_go/csfloattest"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -60,78 +61,270 @@ func TestJsonEncoder(t *testing.T) {
 	t.Log(string(x))
 }
 
-var apiKey = os.Getenv("CSFLOAT_API_KEY")
-var me = sync.OnceValue(func() *csfloat.MeResponse {
-	api := csfloat.New()
-	me, err := api.Me(apiKey)
-	if err != nil {
-		panic(err)
-	}
-	return me
-})
+func newFakeServer(t *testing.T) (*csfloattest.Server, *csfloat.API) {
+	t.Helper()
+
+	server := csfloattest.NewServer()
+	t.Cleanup(server.Close)
+	api := server.API()
+	api.SetReconcilePolicy(csfloat.ReconcilePolicy{Timeout: time.Second})
+	return server, api
+}
 
 func Test_Inventory(t *testing.T) {
 	ass := assert.New(t)
-	api := csfloat.New()
+	server, api := newFakeServer(t)
+	server.AddInventoryItem(csfloat.InventoryItem{Item: csfloat.Item{ID: "1", MarketHashName: "AK-47 | Redline (Field-Tested)"}})
 
-	items, err := api.Inventory(apiKey)
+	items, err := api.Inventory()
 	if ass.NoError(err) {
-		t.Log(items)
-		ass.NotEmpty(items)
+		ass.Len(items.Data, 1)
+		ass.Equal("AK-47 | Redline (Field-Tested)", items.Data[0].MarketHashName)
 	}
 }
 
 func Test_Stall(t *testing.T) {
 	ass := assert.New(t)
-	api := csfloat.New()
+	server, api := newFakeServer(t)
+	me := server.Me()
+	server.AddListing(csfloat.ActiveListing{Price: 100, Seller: csfloat.Seller{SteamID: me.SteamId}})
+	server.AddListing(csfloat.ActiveListing{Price: 200, Seller: csfloat.Seller{SteamID: "someone else"}})
 
-	items, err := api.Stall(apiKey, me().User.SteamId)
+	stall, err := api.Stall(me.SteamId)
 	if ass.NoError(err) {
-		t.Log(items)
-		ass.NotEmpty(items)
+		ass.Len(stall.Items, 1)
+		ass.Equal(1, stall.Count)
+		ass.Equal(uint(100), stall.TotalPrice)
 	}
 }
 
 func Test_Listing(t *testing.T) {
 	ass := assert.New(t)
-	api := csfloat.New()
+	server, api := newFakeServer(t)
+	server.AddListing(csfloat.ActiveListing{ID: "869907646323492200", Price: 100})
 
-	listing, err := api.Listing(apiKey, "869907646323492200")
+	listing, err := api.Listing("869907646323492200")
 	if ass.NoError(err) {
-		t.Log(listing.Item)
-		ass.NotEmpty(listing)
+		ass.Equal(100, listing.Item.Price)
 	}
+
+	_, err = api.Listing("1")
+	ass.ErrorIs(err, csfloat.ErrNotFound)
 }
 
 func Test_Listings(t *testing.T) {
 	ass := assert.New(t)
-	api := csfloat.New()
+	server, api := newFakeServer(t)
+	server.AddListing(csfloat.ActiveListing{Price: 100, Item: csfloat.Item{DefIndex: 7}})
+	server.AddListing(csfloat.ActiveListing{Price: 200, Item: csfloat.Item{DefIndex: 9}})
+	server.AddListing(csfloat.ActiveListing{Price: 300, Item: csfloat.Item{DefIndex: 7}})
 
-	items, err := api.Listings(apiKey, csfloat.ListingsRequest{})
+	items, err := api.Listings(csfloat.ListingsRequest{})
 	if ass.NoError(err) {
-		t.Log(items)
-		ass.NotEmpty(items)
+		ass.Len(items.Data, 3)
+	}
+
+	items, err = api.Listings(csfloat.ListingsRequest{DefIndex: 7, SortBy: csfloat.HighestPrice})
+	if ass.NoError(err) && ass.Len(items.Data, 2) {
+		ass.Equal(300, items.Data[0].Price)
 	}
 }
 
+func Test_Buy(t *testing.T) {
+	ass := assert.New(t)
+	server, api := newFakeServer(t)
+	listing := server.AddListing(csfloat.ActiveListing{Price: 100})
+
+	_, err := api.Buy(csfloat.BuyRequestPayload{ContractIds: []string{listing.ID}, TotalPrice: 100})
+	ass.NoError(err)
+
+	trades, err := api.Trades(csfloat.TradesRequest{})
+	if ass.NoError(err) && ass.Len(trades.Trades, 1) {
+		ass.Equal(listing.ID, trades.Trades[0].Contract.ID)
+		ass.Equal(csfloat.Queued, trades.Trades[0].State)
+	}
+
+	transactions, err := api.Transactions(csfloat.TransactionsRequest{})
+	if ass.NoError(err) && ass.Len(transactions.Transactions, 1) {
+		ass.Equal(-100, transactions.Transactions[0].BalanceOffset)
+	}
+
+	_, err = api.Buy(csfloat.BuyRequestPayload{ContractIds: []string{listing.ID}, TotalPrice: 100})
+	ass.ErrorIs(err, csfloat.ErrAlreadySold)
+}
+
 func Test_BuyIncorrectPrice(t *testing.T) {
-	// ass := assert.New(t)
-	api := csfloat.New()
+	server, api := newFakeServer(t)
+	listing := server.AddListing(csfloat.ActiveListing{Price: 100})
 
-	response, err := api.Buy(apiKey, csfloat.BuyRequestPayload{
-		ContractIds: []string{"861537163265837281"},
+	response, err := api.Buy(csfloat.BuyRequestPayload{
+		ContractIds: []string{listing.ID},
 		TotalPrice:  1,
 	})
-	t.Log(response.Error, err)
+	assert.ErrorIs(t, err, csfloat.ErrPriceChanged)
+	assert.Equal(t, uint(csfloat.ErrorCodePriceChanged), response.Error.Code)
+}
+
+func Test_BuyTimeoutApplied(t *testing.T) {
+	server, api := newFakeServer(t)
+	listing := server.AddListing(csfloat.ActiveListing{Price: 100})
+	server.FailNext(csfloat.RatelimitKeyBuy, csfloattest.Failure{Drop: true, Apply: true})
+
+	_, err := api.Buy(csfloat.BuyRequestPayload{ContractIds: []string{listing.ID}, TotalPrice: 100})
+	var ambiguousErr *csfloat.AmbiguousError
+	if assert.ErrorAs(t, err, &ambiguousErr) {
+		assert.Equal(t, csfloat.OutcomeConfirmed, ambiguousErr.Outcome)
+	}
 }
 
 func Test_BulkDelist(t *testing.T) {
-	api := csfloat.New()
-
-	response, err := api.BulkUnlist(apiKey, []string{
-		"892909870007846613",
-		"892909870007846614",
-		"892909870012040919",
-	}...)
-	t.Log(response.Error, err)
+	ass := assert.New(t)
+	server, api := newFakeServer(t)
+	for _, id := range []string{"1", "2", "3"} {
+		server.AddInventoryItem(csfloat.InventoryItem{Item: csfloat.Item{ID: id}})
+	}
+
+	listed, err := api.BulkList(
+		csfloat.ListRequest{AssetId: "1", BuyNowRequest: &csfloat.BuyNowRequest{Price: 100}},
+		csfloat.ListRequest{AssetId: "2", BuyNowRequest: &csfloat.BuyNowRequest{Price: 100}},
+		csfloat.ListRequest{AssetId: "3", BuyNowRequest: &csfloat.BuyNowRequest{Price: 100}},
+	)
+	if !ass.NoError(err) || !ass.Len(listed.Data, 3) {
+		return
+	}
+
+	_, err = api.BulkUnlist(listed.Data[0].ID, listed.Data[1].ID)
+	ass.NoError(err)
+
+	stall, err := api.Stall(server.Me().SteamId)
+	if ass.NoError(err) && ass.Len(stall.Items, 1) {
+		ass.Equal(listed.Data[2].ID, stall.Items[0].ID)
+	}
+}
+
+func Test_BuyOrders(t *testing.T) {
+	ass := assert.New(t)
+	server, api := newFakeServer(t)
+	server.AddBuyOrder(csfloat.ItemBuyOrder{MarketHashName: "AK-47 | Redline (Field-Tested)", Price: 1000, Quantity: 1})
+
+	simple, err := api.CreateSimpleBuyOrder(csfloat.CreateSimpleBuyOrderPayload{
+		MarketHashName: "AK-47 | Redline (Field-Tested)",
+		MaxPrice:       900,
+		Quantity:       1,
+	})
+	if !ass.NoError(err) {
+		return
+	}
+	advanced, err := api.CreateBuyOrder(csfloat.CreateBuyOrderPayload{
+		Expression: "DefIndex == 7 and PaintIndex == 282",
+		MaxPrice:   800,
+		Quantity:   2,
+	})
+	if !ass.NoError(err) {
+		return
+	}
+	ass.Equal("DefIndex == 7 and PaintIndex == 282", advanced.Expression)
+
+	mine, err := api.MyBuyOrders(csfloat.MyBuyOrdersRequest{})
+	if ass.NoError(err) && ass.Len(mine.Orders, 2) {
+		ass.Equal(uint(2), mine.Count)
+		ass.Equal(advanced.ID, mine.Orders[0].ID)
+		ass.Equal(simple.ID, mine.Orders[1].ID)
+	}
+
+	updated, err := api.UpdateBuyOrder(advanced.ID, csfloat.UpdateBuyOrderPayload{MaxPrice: 850})
+	if ass.NoError(err) {
+		ass.Equal(uint(850), updated.Price)
+		ass.Equal(uint(2), updated.Quantity)
+	}
+
+	_, err = api.BulkDeleteBuyOrders(simple.ID, advanced.ID)
+	ass.NoError(err)
+	ass.Len(server.BuyOrders(), 1)
+
+	_, err = api.BulkDeleteBuyOrders(simple.ID)
+	ass.ErrorIs(err, csfloat.ErrNotFound)
+
+	_, err = api.BulkDeleteBuyOrders()
+	ass.Error(err)
+	ass.Len(server.BuyOrders(), 1)
+}
+
+func Test_Unauthorized(t *testing.T) {
+	server, _ := newFakeServer(t)
+	api := csfloat.New("invalid",
+		csfloat.WithBaseURL(server.BaseURL()),
+		csfloat.WithTransport(server.Client().Transport))
+
+	_, err := api.Me()
+	assert.ErrorIs(t, err, csfloat.ErrUnauthorized)
+
+	// Failures must not bypass authentication.
+	listing := server.AddListing(csfloat.ActiveListing{Price: 100})
+	server.FailNext(csfloat.RatelimitKeyBuy, csfloattest.Failure{Drop: true, Apply: true})
+	_, err = api.Buy(csfloat.BuyRequestPayload{ContractIds: []string{listing.ID}, TotalPrice: 100})
+	assert.ErrorIs(t, err, csfloat.ErrUnauthorized)
+	_, ok := server.Listing(listing.ID)
+	assert.True(t, ok)
+}
+
+func Test_FailureDefaultStatus(t *testing.T) {
+	server, api := newFakeServer(t)
+	server.FailNext(csfloat.RatelimitKeyGetMe, csfloattest.Failure{Message: "oops"})
+
+	_, err := api.Me()
+	var csfloatErr *csfloat.Error
+	if assert.ErrorAs(t, err, &csfloatErr) {
+		assert.Equal(t, uint(http.StatusInternalServerError), csfloatErr.HttpStatus)
+	}
+}
+
+func newTestServer(t *testing.T, handler http.Handler) *httptest.Server {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// newTestAPI creates an API that sends all requests to the given handler.
+func newTestAPI(t *testing.T, handler http.Handler, opts ...csfloat.Option) *csfloat.API {
+	t.Helper()
+
+	server := newTestServer(t, handler)
+	return csfloat.New("", append([]csfloat.Option{
+		csfloat.WithBaseURL(server.URL + "/api/v1"),
+		csfloat.WithTransport(server.Client().Transport),
+	}, opts...)...)
+}
+
+func Test_ContextCancellation(t *testing.T) {
+	block := make(chan struct{})
+	api := newTestAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-block
+	}))
+	// Cleanups run in reverse order, so the handler is unblocked before the
+	// server is closed.
+	t.Cleanup(func() { close(block) })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := api.MeContext(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+// writeJSON writes a successful response with generous ratelimit headers.
+func writeJSON(w http.ResponseWriter, body string) {
+	w.Header().Set("X-Ratelimit-Limit", "100")
+	w.Header().Set("X-Ratelimit-Remaining", "99")
+	w.Header().Set("X-Ratelimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+	w.Write([]byte(body))
+}
+
+// dropConnection closes the connection without responding, simulating a
+// timeout after the request was sent.
+func dropConnection(w http.ResponseWriter) {
+	conn, _, err := http.NewResponseController(w).Hijack()
+	if err == nil {
+		conn.Close()
+	}
 }
@@ -0,0 +1,164 @@
+package csfloattest
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	csfloat "github.com/Bios-Marcel/csfloat_go"
+)
+
+// Failure describes an error the Server responds with instead of handling a
+// request normally.
+type Failure struct {
+	// Status, Code and Message make up the CSFloat error response. Status
+	// defaults to 500.
+	Status  int
+	Code    uint
+	Message string
+	// HTML responds with an HTML error page without ratelimit headers, like
+	// the proxy in front of CSFloat does. Status defaults to 503.
+	HTML bool
+	// Drop closes the connection without responding, as if the request timed
+	// out.
+	Drop bool
+	// Apply processes the request before failing. Combined with Drop, this
+	// simulates a request that was applied, but whose response got lost.
+	Apply bool
+	// Delay is waited before responding.
+	Delay time.Duration
+}
+
+// Common failures, as returned by CSFloat.
+var (
+	AlreadySold = Failure{
+		Status:  http.StatusUnprocessableEntity,
+		Code:    csfloat.ErrorCodeAlreadySold,
+		Message: "listing is no longer available",
+	}
+	PriceChanged = Failure{
+		Status:  http.StatusBadRequest,
+		Code:    csfloat.ErrorCodePriceChanged,
+		Message: "the price of the listing has changed",
+	}
+	InvalidPurchaseState = Failure{
+		Status:  http.StatusUnprocessableEntity,
+		Code:    csfloat.ErrorCodeInvalidPurchaseState,
+		Message: "invalid purchase state",
+	}
+	Unauthorized = Failure{
+		Status:  http.StatusUnauthorized,
+		Message: "invalid api key",
+	}
+	ServiceUnavailable = Failure{
+		Status: http.StatusServiceUnavailable,
+		HTML:   true,
+	}
+	DropConnection = Failure{
+		Drop: true,
+	}
+)
+
+// FailNext makes the next requests to the given bucket fail, one failure per
+// request, in the given order.
+func (server *Server) FailNext(bucket csfloat.RatelimitBucketKey, failures ...Failure) {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+
+	server.failures[bucket] = append(server.failures[bucket], failures...)
+}
+
+// popFailure requires the caller to hold the lock.
+func (server *Server) popFailure(bucket csfloat.RatelimitBucketKey) (Failure, bool) {
+	failures := server.failures[bucket]
+	if len(failures) == 0 {
+		return Failure{}, false
+	}
+	server.failures[bucket] = failures[1:]
+	return failures[0], true
+}
+
+func (failure Failure) apiError() *apiError {
+	status := failure.Status
+	if status == 0 {
+		status = http.StatusInternalServerError
+	}
+	return &apiError{status: status, code: failure.Code, message: failure.Message}
+}
+
+func (failure Failure) write(writer http.ResponseWriter) {
+	if failure.Drop {
+		conn, _, err := http.NewResponseController(writer).Hijack()
+		if err == nil {
+			conn.Close()
+		}
+		return
+	}
+
+	if failure.HTML {
+		status := failure.Status
+		if status == 0 {
+			status = http.StatusServiceUnavailable
+		}
+		header := writer.Header()
+		header.Del("X-Ratelimit-Limit")
+		header.Del("X-Ratelimit-Remaining")
+		header.Del("X-Ratelimit-Reset")
+		header.Set("Content-Type", "text/html")
+		writer.WriteHeader(status)
+		writer.Write([]byte("<html><body><h1>" + strconv.Itoa(status) + " " +
+			http.StatusText(status) + "</h1></body></html>"))
+		return
+	}
+
+	writeError(writer, failure.apiError())
+}
+
+// ratelimit is the state of a single bucket.
+type ratelimit struct {
+	limit     uint
+	window    time.Duration
+	remaining uint
+	reset     time.Time
+}
+
+// SetRatelimit configures the limit of the given bucket. By default, each
+// bucket allows 1000 requests per 10 seconds, so that clients respecting the
+// ratelimits don't slow down tests.
+func (server *Server) SetRatelimit(bucket csfloat.RatelimitBucketKey, limit uint, window time.Duration) {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+
+	server.ratelimits[bucket] = &ratelimit{
+		limit:     limit,
+		window:    window,
+		remaining: limit,
+		reset:     time.Now().Add(window),
+	}
+}
+
+// consumeRatelimit writes the ratelimit headers and reports whether the
+// request is within the limit. It requires the caller to hold the lock.
+func (server *Server) consumeRatelimit(writer http.ResponseWriter, bucket csfloat.RatelimitBucketKey) bool {
+	state, ok := server.ratelimits[bucket]
+	if !ok {
+		state = &ratelimit{limit: 1000, window: 10 * time.Second}
+		server.ratelimits[bucket] = state
+	}
+
+	now := time.Now()
+	if !now.Before(state.reset) {
+		state.remaining = state.limit
+		state.reset = now.Add(state.window)
+	}
+
+	allowed := state.remaining > 0
+	if allowed {
+		state.remaining--
+	}
+
+	writer.Header().Set("X-Ratelimit-Limit", strconv.FormatUint(uint64(state.limit), 10))
+	writer.Header().Set("X-Ratelimit-Remaining", strconv.FormatUint(uint64(state.remaining), 10))
+	writer.Header().Set("X-Ratelimit-Reset", strconv.FormatInt(state.reset.Unix(), 10))
+	return allowed
+}
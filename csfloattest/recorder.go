@@ -0,0 +1,269 @@
+package csfloattest
+
+import (
+	"bytes"
+	"encoding/json/jsontext"
+	json "encoding/json/v2"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"sync"
+)
+
+// Cassette is a recording of HTTP exchanges with CSFloat, stored as JSON.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a single recorded request and its response.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+type RecordedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// LoadCassette reads a cassette written by Recorder.Save.
+func LoadCassette(path string) (*Cassette, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening cassette: %w", err)
+	}
+	defer file.Close()
+
+	var cassette Cassette
+	if err := json.UnmarshalRead(file, &cassette); err != nil {
+		return nil, fmt.Errorf("error decoding cassette: %w", err)
+	}
+	return &cassette, nil
+}
+
+// Save writes the cassette as indented JSON.
+func (cassette *Cassette) Save(path string) error {
+	data, err := json.Marshal(cassette, jsontext.WithIndent("  "))
+	if err != nil {
+		return fmt.Errorf("error encoding cassette: %w", err)
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// secretHeaders are never recorded.
+var secretHeaders = []string{"Authorization", "Cookie", "Set-Cookie"}
+
+// steamIdPattern matches 64 bit steam IDs of individual accounts.
+var steamIdPattern = regexp.MustCompile(`7656119\d{10}`)
+
+// Recorder is an http.RoundTripper that passes requests on to the underlying
+// transport and records all exchanges, so they can be replayed via Replayer.
+//
+// Secrets are scrubbed before recording: the Authorization and cookie headers
+// are dropped and every steam ID is replaced by a placeholder. Placeholders
+// are consistent within a recording, so the same steam ID always maps to the
+// same placeholder.
+type Recorder struct {
+	transport http.RoundTripper
+
+	lock     sync.Mutex
+	cassette Cassette
+	steamIds map[string]string
+}
+
+// NewRecorder creates a Recorder using the given transport. If transport is
+// nil, http.DefaultTransport is used.
+func NewRecorder(transport http.RoundTripper) *Recorder {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return &Recorder{
+		transport: transport,
+		steamIds:  make(map[string]string),
+	}
+}
+
+func (recorder *Recorder) RoundTrip(request *http.Request) (*http.Response, error) {
+	var requestBody []byte
+	if request.Body != nil {
+		var err error
+		requestBody, err = io.ReadAll(request.Body)
+		request.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error reading request body: %w", err)
+		}
+		// A RoundTripper must not modify the request, so we send a copy.
+		request = request.Clone(request.Context())
+		request.Body = io.NopCloser(bytes.NewReader(requestBody))
+	}
+
+	response, err := recorder.transport.RoundTrip(request)
+	if err != nil {
+		// Failed exchanges can't be replayed, so we don't record them.
+		return nil, err
+	}
+
+	responseBody, err := io.ReadAll(response.Body)
+	response.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("error reading response body: %w", err)
+	}
+	response.Body = io.NopCloser(bytes.NewReader(responseBody))
+
+	recorder.lock.Lock()
+	defer recorder.lock.Unlock()
+
+	recorder.cassette.Interactions = append(recorder.cassette.Interactions, Interaction{
+		Request: RecordedRequest{
+			Method: request.Method,
+			URL:    recorder.scrub(request.URL.String()),
+			Header: recorder.scrubHeader(request.Header),
+			Body:   recorder.scrub(string(requestBody)),
+		},
+		Response: RecordedResponse{
+			StatusCode: response.StatusCode,
+			Header:     recorder.scrubHeader(response.Header),
+			Body:       recorder.scrub(string(responseBody)),
+		},
+	})
+	return response, nil
+}
+
+// Cassette returns a copy of everything recorded so far.
+func (recorder *Recorder) Cassette() *Cassette {
+	recorder.lock.Lock()
+	defer recorder.lock.Unlock()
+
+	return &Cassette{
+		Interactions: append([]Interaction(nil), recorder.cassette.Interactions...),
+	}
+}
+
+// Save writes everything recorded so far to the given path.
+func (recorder *Recorder) Save(path string) error {
+	return recorder.Cassette().Save(path)
+}
+
+// scrub replaces all steam IDs with placeholders. It requires the caller to
+// hold the lock.
+func (recorder *Recorder) scrub(text string) string {
+	return steamIdPattern.ReplaceAllStringFunc(text, func(steamId string) string {
+		placeholder, ok := recorder.steamIds[steamId]
+		if !ok {
+			placeholder = "7656119" + fmt.Sprintf("%010d", len(recorder.steamIds)+1)
+			recorder.steamIds[steamId] = placeholder
+		}
+		return placeholder
+	})
+}
+
+// scrubHeader requires the caller to hold the lock.
+func (recorder *Recorder) scrubHeader(header http.Header) http.Header {
+	scrubbed := make(http.Header, len(header))
+	for key, values := range header {
+		for _, value := range values {
+			scrubbed.Add(key, recorder.scrub(value))
+		}
+	}
+	for _, key := range secretHeaders {
+		scrubbed.Del(key)
+	}
+	return scrubbed
+}
+
+// ErrNoInteraction is returned by Replayer if no recorded interaction matches
+// a request.
+var ErrNoInteraction = errors.New("no matching interaction recorded")
+
+// Replayer is an http.RoundTripper that answers requests with the responses
+// from a cassette, without any network access. Requests are matched by
+// method, path, query and body. Each interaction is only replayed once, in
+// the order of recording, so repeated requests get the responses in the
+// original order.
+type Replayer struct {
+	lock     sync.Mutex
+	cassette *Cassette
+	used     []bool
+}
+
+// NewReplayer creates a Replayer for the given cassette.
+func NewReplayer(cassette *Cassette) *Replayer {
+	return &Replayer{
+		cassette: cassette,
+		used:     make([]bool, len(cassette.Interactions)),
+	}
+}
+
+func (replayer *Replayer) RoundTrip(request *http.Request) (*http.Response, error) {
+	var requestBody []byte
+	if request.Body != nil {
+		var err error
+		requestBody, err = io.ReadAll(request.Body)
+		request.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error reading request body: %w", err)
+		}
+	}
+
+	replayer.lock.Lock()
+	defer replayer.lock.Unlock()
+
+	// The host is ignored, so that cassettes recorded against one server can
+	// be replayed with a client configured for another.
+	requestURI := request.URL.RequestURI()
+	for index, interaction := range replayer.cassette.Interactions {
+		if replayer.used[index] ||
+			interaction.Request.Method != request.Method ||
+			interaction.Request.Body != string(requestBody) {
+			continue
+		}
+		if recordedURL, err := url.Parse(interaction.Request.URL); err != nil ||
+			recordedURL.RequestURI() != requestURI {
+			continue
+		}
+
+		replayer.used[index] = true
+		recorded := interaction.Response
+		return &http.Response{
+			Status:        strconv.Itoa(recorded.StatusCode) + " " + http.StatusText(recorded.StatusCode),
+			StatusCode:    recorded.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        recorded.Header.Clone(),
+			Body:          io.NopCloser(bytes.NewReader([]byte(recorded.Body))),
+			ContentLength: int64(len(recorded.Body)),
+			Request:       request,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s %s", ErrNoInteraction, request.Method, requestURI)
+}
+
+// Unused returns all interactions that haven't been replayed yet. This can
+// be used to assert that a test made all expected requests.
+func (replayer *Replayer) Unused() []Interaction {
+	replayer.lock.Lock()
+	defer replayer.lock.Unlock()
+
+	var unused []Interaction
+	for index, interaction := range replayer.cassette.Interactions {
+		if !replayer.used[index] {
+			unused = append(unused, interaction)
+		}
+	}
+	return unused
+}
@@ -0,0 +1,71 @@
+package csfloattest_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	csfloat "github.com/Bios-Marcel/csfloat_go"
+	"github.com/Bios-Marcel/csfloat_go/csfloattest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RecordReplay(t *testing.T) {
+	ass := assert.New(t)
+
+	server := csfloattest.NewServer()
+	defer server.Close()
+	realSteamId := server.Me().SteamId
+	server.AddListing(csfloat.ActiveListing{
+		ID:     "1",
+		Price:  100,
+		Item:   csfloat.Item{MarketHashName: "AK-47 | Redline (Field-Tested)"},
+		Seller: csfloat.Seller{SteamID: realSteamId},
+	})
+
+	recorder := csfloattest.NewRecorder(server.Client().Transport)
+	api := server.API(csfloat.WithTransport(recorder))
+	me, err := api.Me()
+	require.NoError(t, err)
+	_, err = api.Stall(me.User.SteamId)
+	require.NoError(t, err)
+	_, err = api.Listing("1")
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	require.NoError(t, recorder.Save(path))
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	ass.NotContains(string(raw), csfloattest.APIKey)
+	ass.NotContains(string(raw), realSteamId)
+	ass.Contains(string(raw), "X-Ratelimit-Remaining")
+
+	cassette, err := csfloattest.LoadCassette(path)
+	require.NoError(t, err)
+	ass.Len(cassette.Interactions, 3)
+
+	// The replayed steam ID is the placeholder, which is then used for
+	// requesting the stall, just like in the recording.
+	replayer := csfloattest.NewReplayer(cassette)
+	api = csfloat.New("", csfloat.WithTransport(replayer))
+	me, err = api.Me()
+	require.NoError(t, err)
+	ass.NotEqual(realSteamId, me.User.SteamId)
+	ass.True(strings.HasPrefix(me.User.SteamId, "7656119"))
+	ass.Equal(uint(1000), me.Ratelimits.Limit)
+
+	stall, err := api.Stall(me.User.SteamId)
+	if ass.NoError(err) && ass.Len(stall.Items, 1) {
+		ass.Equal(me.User.SteamId, stall.Items[0].Seller.SteamID)
+		ass.Equal("AK-47 | Redline (Field-Tested)", stall.Items[0].Item.MarketHashName)
+	}
+
+	ass.Len(replayer.Unused(), 1)
+	_, err = api.Listing("1")
+	ass.NoError(err)
+	_, err = api.Listing("1")
+	ass.ErrorIs(err, csfloattest.ErrNoInteraction)
+	ass.Empty(replayer.Unused())
+}
@@ -0,0 +1,1485 @@
+// Package csfloattest provides an in-process fake of the CSFloat API for
+// testing code built on top of csfloat, without touching a real account.
+//
+// The fake keeps its state in memory, so buying a listing for example creates
+// a trade and a transaction, which are returned by subsequent requests. The
+// behaviour is modelled after the real API as far as it is known, but is by
+// no means complete.
+package csfloattest
+
+import (
+	json "encoding/json/v2"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	csfloat "github.com/Bios-Marcel/csfloat_go"
+)
+
+// APIKey is the only API key accepted by the Server.
+const APIKey = "csfloattest-api-key"
+
+// ListingStateSold is set on listings that were bought via the Server. The
+// real state name is unknown.
+const ListingStateSold csfloat.ListingState = "sold"
+
+// Server is a fake CSFloat API server. It is safe for concurrent use.
+type Server struct {
+	*httptest.Server
+
+	lock         sync.Mutex
+	nextID       uint64
+	me           csfloat.MeUser
+	listings     map[string]*csfloat.ActiveListing
+	inventory    []*csfloat.InventoryItem
+	trades       []csfloat.Trade
+	transactions []csfloat.Transaction
+	buyOrders    []*csfloat.ItemBuyOrder
+	myBuyOrders  map[string]struct{}
+	bids         []*bid
+	offers       []*csfloat.Offer
+	watchlist    map[string]struct{}
+	failures     map[csfloat.RatelimitBucketKey][]Failure
+	ratelimits   map[csfloat.RatelimitBucketKey]*ratelimit
+}
+
+// NewServer starts a new Server. The caller should call Close when done.
+func NewServer() *Server {
+	server := &Server{
+		nextID: 1_000_000,
+		me: csfloat.MeUser{
+			SteamId: "76561198000000001",
+			Balance: 100_000,
+		},
+		listings:    make(map[string]*csfloat.ActiveListing),
+		watchlist:   make(map[string]struct{}),
+		myBuyOrders: make(map[string]struct{}),
+		failures:    make(map[csfloat.RatelimitBucketKey][]Failure),
+		ratelimits:  make(map[csfloat.RatelimitBucketKey]*ratelimit),
+	}
+
+	mux := http.NewServeMux()
+	server.route(mux, "GET /api/v1/me", csfloat.RatelimitKeyGetMe, server.handleMe)
+	server.route(mux, "GET /api/v1/me/inventory", csfloat.RatelimitKeyGetInventory, server.handleInventory)
+	server.route(mux, "GET /api/v1/me/trades", csfloat.RatelimitKeyGetTrades, server.handleTrades)
+	server.route(mux, "GET /api/v1/me/transactions", csfloat.RatelimitKeyGetTransactions, server.handleTransactions)
+	server.route(mux, "GET /api/v1/users/{steamId}/stall", csfloat.RatelimitKeyGetStall, server.handleStall)
+	server.route(mux, "GET /api/v1/listings", csfloat.RatelimitKeyGetListings, server.handleListings)
+	server.route(mux, "POST /api/v1/listings", csfloat.RatelimitKeyCreateListing, server.handleList)
+	server.route(mux, "POST /api/v1/listings/bulk-list", csfloat.RatelimitKeyBulkList, server.handleBulkList)
+	server.route(mux, "PATCH /api/v1/listings/bulk-delist", csfloat.RatelimitKeyBulkUnlist, server.handleBulkUnlist)
+	server.route(mux, "POST /api/v1/listings/buy", csfloat.RatelimitKeyBuy, server.handleBuy)
+	server.route(mux, "GET /api/v1/listings/{id}", csfloat.RatelimitKeyGetListing, server.handleListing)
+	server.route(mux, "PATCH /api/v1/listings/{id}", csfloat.RatelimitKeyUpdateListing, server.handleUpdateListing)
+	server.route(mux, "DELETE /api/v1/listings/{id}", csfloat.RatelimitKeyUnlist, server.handleUnlist)
+	server.route(mux, "GET /api/v1/listings/{id}/similar", csfloat.RatelimitKeyGetSimilar, server.handleSimilar)
+	server.route(mux, "POST /api/v1/listings/{id}/bid", csfloat.RatelimitKeyPlaceBid, server.handlePlaceBid)
+	server.route(mux, "GET /api/v1/listings/{id}/bids", csfloat.RatelimitKeyGetBids, server.handleBids)
+	server.route(mux, "GET /api/v1/me/bids", csfloat.RatelimitKeyGetMyBids, server.handleMyBids)
+	server.route(mux, "POST /api/v1/offers", csfloat.RatelimitKeySendOffer, server.handleSendOffer)
+	server.route(mux, "GET /api/v1/me/offers", csfloat.RatelimitKeyGetOffers, server.handleOffers)
+	server.route(mux, "POST /api/v1/offers/{id}/accept", csfloat.RatelimitKeyAcceptOffer, server.handleAcceptOffer)
+	server.route(mux, "DELETE /api/v1/offers/{id}", csfloat.RatelimitKeyDeclineOffer, server.handleDeclineOffer)
+	server.route(mux, "POST /api/v1/offers/{id}/counter-offer", csfloat.RatelimitKeyCounterOffer, server.handleCounterOffer)
+	server.route(mux, "GET /api/v1/listings/{id}/buy-orders", csfloat.RatelimitKeyGetListingBuyOrders, server.handleListingBuyOrders)
+	server.route(mux, "POST /api/v1/listings/{id}/watchlist", csfloat.RatelimitKeyWatch, server.handleWatch)
+	server.route(mux, "DELETE /api/v1/listings/{id}/watchlist", csfloat.RatelimitKeyUnwatch, server.handleUnwatch)
+	server.route(mux, "POST /api/v1/buy-orders", csfloat.RatelimitKeyCreateBuyOrder, server.handleCreateBuyOrder)
+	server.route(mux, "PATCH /api/v1/buy-orders/{id}", csfloat.RatelimitKeyUpdateBuyOrder, server.handleUpdateBuyOrder)
+	server.route(mux, "DELETE /api/v1/buy-orders/{id}", csfloat.RatelimitKeyDeleteBuyOrder, server.handleDeleteBuyOrder)
+	server.route(mux, "POST /api/v1/buy-orders/bulk-delete", csfloat.RatelimitKeyBulkDeleteBuyOrders, server.handleBulkDeleteBuyOrders)
+	server.route(mux, "GET /api/v1/me/buy-orders", csfloat.RatelimitKeyGetMyBuyOrders, server.handleMyBuyOrders)
+	server.route(mux, "GET /api/v1/buy-orders/item", csfloat.RatelimitKeyGetItemBuyOrders, server.handleItemBuyOrders)
+	server.route(mux, "POST /api/v1/buy-orders/similar-orders", csfloat.RatelimitKeyGetSimpleItemBuyOrders, server.handleSimilarBuyOrders)
+
+	server.Server = httptest.NewServer(mux)
+	return server
+}
+
+// BaseURL returns the URL to be passed to csfloat.WithBaseURL.
+func (server *Server) BaseURL() string {
+	return server.URL + "/api/v1"
+}
+
+// API returns a client talking to this server, authenticated with APIKey.
+// The given options are applied last.
+func (server *Server) API(opts ...csfloat.Option) *csfloat.API {
+	return csfloat.New(APIKey, append([]csfloat.Option{
+		csfloat.WithBaseURL(server.BaseURL()),
+		csfloat.WithTransport(server.Client().Transport),
+	}, opts...)...)
+}
+
+// apiError is rendered in the CSFloat error format.
+type apiError struct {
+	status  int
+	code    uint
+	message string
+}
+
+func errorf(status int, code uint, format string, args ...any) *apiError {
+	return &apiError{status: status, code: code, message: fmt.Sprintf(format, args...)}
+}
+
+func errNotFound(what string) *apiError {
+	return errorf(http.StatusNotFound, 0, "%s not found", what)
+}
+
+// handlerFunc is called with the server lock held. The returned body is
+// encoded as JSON, unless an error is returned.
+type handlerFunc func(request *http.Request) (any, *apiError)
+
+func (server *Server) route(
+	mux *http.ServeMux,
+	pattern string,
+	bucket csfloat.RatelimitBucketKey,
+	handler handlerFunc,
+) {
+	mux.HandleFunc(pattern, func(writer http.ResponseWriter, request *http.Request) {
+		server.lock.Lock()
+		failure, hasFailure := server.popFailure(bucket)
+		server.lock.Unlock()
+
+		if hasFailure && failure.Delay > 0 {
+			select {
+			case <-request.Context().Done():
+				return
+			case <-time.After(failure.Delay):
+			}
+		}
+
+		server.lock.Lock()
+		defer server.lock.Unlock()
+
+		if request.Header.Get("Authorization") != APIKey {
+			writeError(writer, errorf(http.StatusUnauthorized, 0, "invalid api key"))
+			return
+		}
+
+		if !server.consumeRatelimit(writer, bucket) {
+			writeError(writer, errorf(http.StatusTooManyRequests, 0, "too many requests"))
+			return
+		}
+
+		if hasFailure {
+			if failure.Apply {
+				handler(request)
+			}
+			failure.write(writer)
+			return
+		}
+
+		body, err := handler(request)
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+		encoded, marshalErr := json.Marshal(body)
+		if marshalErr != nil {
+			writeError(writer, errorf(http.StatusInternalServerError, 0, "error encoding response: %v", marshalErr))
+			return
+		}
+		writer.Header().Set("Content-Type", "application/json")
+		writer.Write(encoded)
+	})
+}
+
+func writeError(writer http.ResponseWriter, err *apiError) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(err.status)
+	json.MarshalWrite(writer, map[string]any{
+		"code":    err.code,
+		"message": err.message,
+	})
+}
+
+// decode reads the JSON request body into target.
+func decode(request *http.Request, target any) *apiError {
+	if err := json.UnmarshalRead(request.Body, target); err != nil {
+		return errorf(http.StatusBadRequest, 0, "invalid request body: %v", err)
+	}
+	return nil
+}
+
+// id returns a new unique numeric ID, just like CSFloat uses.
+func (server *Server) id() string {
+	server.nextID++
+	return strconv.FormatUint(server.nextID, 10)
+}
+
+// queryUint returns the numeric query parameter, or fallback if absent.
+func queryUint(request *http.Request, key string, fallback uint) (uint, *apiError) {
+	value := request.URL.Query().Get(key)
+	if value == "" {
+		return fallback, nil
+	}
+	parsed, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, errorf(http.StatusBadRequest, 0, "invalid %s: %v", key, err)
+	}
+	return uint(parsed), nil
+}
+
+// page returns the items on the given page.
+func page[T any](items []T, page, limit uint) []T {
+	start := min(int(page*limit), len(items))
+	end := min(start+int(limit), len(items))
+	return items[start:end]
+}
+
+// SetMe replaces the user returned by the me endpoint. Its SteamId
+// identifies our own stall.
+func (server *Server) SetMe(user csfloat.MeUser) {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+
+	server.me = user
+}
+
+// Me returns the current state of the own user, including the balance.
+func (server *Server) Me() csfloat.MeUser {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+
+	return server.me
+}
+
+// AddListing adds a listing to the market. If no ID is set, one is
+// generated. If no state is set, it is listed. Listings whose seller has our
+// steam ID show up in our stall. The resulting listing is returned.
+func (server *Server) AddListing(listing csfloat.ActiveListing) csfloat.ActiveListing {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+
+	if listing.ID == "" {
+		listing.ID = server.id()
+	}
+	if listing.State == "" {
+		listing.State = csfloat.ListingStateListed
+	}
+	if listing.Type == "" {
+		listing.Type = csfloat.BuyNow
+	}
+	if listing.CreatedAt.IsZero() {
+		listing.CreatedAt = time.Now()
+	}
+	if listing.Type == csfloat.Auction {
+		details := csfloat.AuctionDetails{}
+		if listing.AuctionDetails != nil {
+			details = *listing.AuctionDetails
+		}
+		if details.ExpiresAt.IsZero() {
+			details.ExpiresAt = listing.CreatedAt.AddDate(0, 0, 7)
+		}
+		if details.MinNextBid == 0 {
+			details.MinNextBid = uint(listing.Price)
+		}
+		listing.AuctionDetails = &details
+	}
+	server.listings[listing.ID] = &listing
+	return cloneListing(listing)
+}
+
+// cloneListing copies the listing, so it can be handed out without holding
+// the lock.
+func cloneListing(listing csfloat.ActiveListing) csfloat.ActiveListing {
+	if listing.AuctionDetails != nil {
+		details := *listing.AuctionDetails
+		if details.TopBid != nil {
+			topBid := *details.TopBid
+			details.TopBid = &topBid
+		}
+		listing.AuctionDetails = &details
+	}
+	return listing
+}
+
+// Listing returns the current state of the listing. Unlisted listings are
+// deleted, bought listings have ListingStateSold.
+func (server *Server) Listing(id string) (csfloat.ActiveListing, bool) {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+
+	listing, ok := server.listings[id]
+	if !ok {
+		return csfloat.ActiveListing{}, false
+	}
+	return cloneListing(*listing), true
+}
+
+// OtherSteamID is the default steam ID of other users, for example of the
+// buyer of offers added via AddOffer.
+const OtherSteamID = "76561198000000002"
+
+// AddOffer adds an offer on the listing with the given ContractID. By default,
+// it is an active offer by OtherSteamID, valid for a day. Offers on our own
+// listings show up as incoming offers.
+func (server *Server) AddOffer(offer csfloat.Offer) (csfloat.Offer, error) {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+
+	listing, ok := server.listings[offer.ContractID]
+	if !ok {
+		return csfloat.Offer{}, fmt.Errorf("listing %s not found", offer.ContractID)
+	}
+	if offer.ID == "" {
+		offer.ID = server.id()
+	}
+	if offer.CreatedAt.IsZero() {
+		offer.CreatedAt = time.Now()
+	}
+	if offer.ExpiresAt.IsZero() {
+		offer.ExpiresAt = offer.CreatedAt.Add(24 * time.Hour)
+	}
+	if offer.Type == "" {
+		offer.Type = csfloat.BuyerOffer
+	}
+	if offer.State == "" {
+		offer.State = csfloat.OfferActive
+	}
+	if offer.BuyerID == "" {
+		offer.BuyerID = OtherSteamID
+	}
+	offer.SellerID = listing.Seller.SteamID
+	server.offers = append(server.offers, &offer)
+	return server.withContract(offer), nil
+}
+
+// Offer returns the current state of the offer.
+func (server *Server) Offer(id string) (csfloat.Offer, bool) {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+
+	index := slices.IndexFunc(server.offers, func(offer *csfloat.Offer) bool {
+		return offer.ID == id
+	})
+	if index == -1 {
+		return csfloat.Offer{}, false
+	}
+	return server.withContract(*server.offers[index]), true
+}
+
+// AddBid bids on an auction on behalf of another user. The auction's state is
+// updated just like for bids placed via the API.
+func (server *Server) AddBid(listingId string, maxPrice uint) (csfloat.Bid, error) {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+
+	placed, err := server.placeBid(listingId, maxPrice, false)
+	if err != nil {
+		return csfloat.Bid{}, errors.New(err.message)
+	}
+	return placed.Bid, nil
+}
+
+// AddInventoryItem adds an item to our inventory, making it listable.
+func (server *Server) AddInventoryItem(item csfloat.InventoryItem) {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+
+	server.inventory = append(server.inventory, &item)
+}
+
+// AddTrade adds a trade, as if something was bought or sold.
+func (server *Server) AddTrade(trade csfloat.Trade) {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+
+	if trade.ID == "" {
+		trade.ID = server.id()
+	}
+	server.trades = append(server.trades, trade)
+}
+
+// Trades returns all trades, newest first.
+func (server *Server) Trades() []csfloat.Trade {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+
+	return server.sortedTrades()
+}
+
+// AddTransaction adds an entry to our transaction history.
+func (server *Server) AddTransaction(transaction csfloat.Transaction) {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+
+	if transaction.ID == "" {
+		transaction.ID = server.id()
+	}
+	server.transactions = append(server.transactions, transaction)
+}
+
+// AddBuyOrder adds a buy order of any user to the market. If no ID is set,
+// one is generated.
+func (server *Server) AddBuyOrder(order csfloat.ItemBuyOrder) csfloat.ItemBuyOrder {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+
+	if order.ID == "" {
+		order.ID = server.id()
+	}
+	server.buyOrders = append(server.buyOrders, &order)
+	return order
+}
+
+// AddMyBuyOrder is like AddBuyOrder, but the buy order is one of ours.
+func (server *Server) AddMyBuyOrder(order csfloat.ItemBuyOrder) csfloat.ItemBuyOrder {
+	order = server.AddBuyOrder(order)
+
+	server.lock.Lock()
+	defer server.lock.Unlock()
+
+	server.myBuyOrders[order.ID] = struct{}{}
+	return order
+}
+
+// BuyOrders returns all buy orders.
+func (server *Server) BuyOrders() []csfloat.ItemBuyOrder {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+
+	orders := make([]csfloat.ItemBuyOrder, 0, len(server.buyOrders))
+	for _, order := range server.buyOrders {
+		orders = append(orders, *order)
+	}
+	return orders
+}
+
+// Watchlist returns the IDs of all watched listings.
+func (server *Server) Watchlist() []string {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+
+	ids := make([]string, 0, len(server.watchlist))
+	for id := range server.watchlist {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+func (server *Server) handleMe(request *http.Request) (any, *apiError) {
+	return map[string]any{"user": server.me}, nil
+}
+
+func (server *Server) handleInventory(request *http.Request) (any, *apiError) {
+	limit, err := queryUint(request, "limit", 40)
+	if err != nil {
+		return nil, err
+	}
+	offset, err := queryUint(request, "offset", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]csfloat.InventoryItem, 0, len(server.inventory))
+	for _, item := range server.inventory {
+		items = append(items, *item)
+	}
+	slices.SortFunc(items, func(a, b csfloat.InventoryItem) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+	start := min(int(offset), len(items))
+	end := min(start+int(limit), len(items))
+	return items[start:end], nil
+}
+
+func (server *Server) sortedTrades() []csfloat.Trade {
+	trades := slices.Clone(server.trades)
+	slices.SortStableFunc(trades, func(a, b csfloat.Trade) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+	return trades
+}
+
+func (server *Server) handleTrades(request *http.Request) (any, *apiError) {
+	pageIndex, err := queryUint(request, "page", 0)
+	if err != nil {
+		return nil, err
+	}
+	limit, err := queryUint(request, "limit", 100)
+	if err != nil {
+		return nil, err
+	}
+
+	trades := server.sortedTrades()
+	if states := request.URL.Query().Get("state"); states != "" {
+		allowed := strings.Split(states, ",")
+		trades = slices.DeleteFunc(trades, func(trade csfloat.Trade) bool {
+			return !slices.Contains(allowed, string(trade.State))
+		})
+	}
+
+	return map[string]any{
+		"trades": page(trades, pageIndex, limit),
+		"count":  len(trades),
+	}, nil
+}
+
+func (server *Server) handleTransactions(request *http.Request) (any, *apiError) {
+	pageIndex, err := queryUint(request, "page", 0)
+	if err != nil {
+		return nil, err
+	}
+	limit, err := queryUint(request, "limit", 100)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions := slices.Clone(server.transactions)
+	slices.SortStableFunc(transactions, func(a, b csfloat.Transaction) int {
+		if request.URL.Query().Get("order") == string(csfloat.OrderAsc) {
+			return a.CreatedAt.Compare(b.CreatedAt)
+		}
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+
+	return map[string]any{
+		"transactions": page(transactions, pageIndex, limit),
+		"count":        len(transactions),
+	}, nil
+}
+
+// listed returns all publicly listed listings matching the filter, newest
+// first.
+func (server *Server) listed(filter func(*csfloat.ActiveListing) bool) []csfloat.ActiveListing {
+	var listings []csfloat.ActiveListing
+	for _, listing := range server.listings {
+		if listing.State == csfloat.ListingStateListed && filter(listing) {
+			listings = append(listings, *listing)
+		}
+	}
+	slices.SortFunc(listings, func(a, b csfloat.ActiveListing) int {
+		if order := b.CreatedAt.Compare(a.CreatedAt); order != 0 {
+			return order
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return listings
+}
+
+func (server *Server) handleStall(request *http.Request) (any, *apiError) {
+	query := request.URL.Query()
+	limit, err := queryUint(request, "limit", 40)
+	if err != nil {
+		return nil, err
+	}
+	if limit > 50 {
+		return nil, errorf(http.StatusBadRequest, 0, "limit must be at most 50")
+	}
+
+	steamId := request.PathValue("steamId")
+	listingType := csfloat.ListingType(query.Get("type"))
+	listings := server.listed(func(listing *csfloat.ActiveListing) bool {
+		return listing.Seller.SteamID == steamId &&
+			(listingType == "" || listing.Type == listingType)
+	})
+	sortListings(listings, csfloat.SortListingsBy(query.Get("sort_by")))
+
+	var totalPrice int
+	for _, listing := range listings {
+		totalPrice += listing.Price
+	}
+	data, cursor, err := cursorPage(request, listings, limit)
+	if err != nil {
+		return nil, err
+	}
+	response := map[string]any{
+		"data":        data,
+		"total_count": len(listings),
+		"total_price": totalPrice,
+	}
+	if cursor != "" {
+		response["cursor"] = cursor
+	}
+	return response, nil
+}
+
+func (server *Server) handleListings(request *http.Request) (any, *apiError) {
+	query := request.URL.Query()
+	limit, err := queryUint(request, "limit", 40)
+	if err != nil {
+		return nil, err
+	}
+	if limit > 50 {
+		return nil, errorf(http.StatusBadRequest, 0, "limit must be at most 50")
+	}
+	var filters []func(*csfloat.ActiveListing) bool
+	for _, key := range []string{"def_index", "paint_index", "sticker_index", "keychain_index"} {
+		value, err := queryUint(request, key, 0)
+		if err != nil {
+			return nil, err
+		}
+		if value == 0 {
+			continue
+		}
+		filters = append(filters, func(listing *csfloat.ActiveListing) bool {
+			switch key {
+			case "def_index":
+				return listing.Item.DefIndex == value
+			case "paint_index":
+				return listing.Item.PaintIndex == value
+			case "sticker_index":
+				return listing.Item.StickerIndex == value
+			default:
+				return listing.Item.CharmIndex == value
+			}
+		})
+	}
+	for _, key := range []string{"min_price", "max_price"} {
+		value, err := queryUint(request, key, 0)
+		if err != nil {
+			return nil, err
+		}
+		if value == 0 {
+			continue
+		}
+		filters = append(filters, func(listing *csfloat.ActiveListing) bool {
+			if key == "min_price" {
+				return listing.Price >= int(value)
+			}
+			return listing.Price <= int(value)
+		})
+	}
+	for _, key := range []string{"min_float", "max_float"} {
+		if query.Get(key) == "" {
+			continue
+		}
+		value, parseErr := strconv.ParseFloat(query.Get(key), 64)
+		if parseErr != nil {
+			return nil, errorf(http.StatusBadRequest, 0, "invalid %s", key)
+		}
+		filters = append(filters, func(listing *csfloat.ActiveListing) bool {
+			if key == "min_float" {
+				return listing.Item.Float >= value
+			}
+			return listing.Item.Float <= value
+		})
+	}
+	if listingType := query.Get("type"); listingType != "" {
+		filters = append(filters, func(listing *csfloat.ActiveListing) bool {
+			return string(listing.Type) == listingType
+		})
+	}
+	if name := query.Get("market_hash_name"); name != "" {
+		filters = append(filters, func(listing *csfloat.ActiveListing) bool {
+			return listing.Item.MarketHashName == name
+		})
+	}
+	if sellerId := query.Get("user_id"); sellerId != "" {
+		filters = append(filters, func(listing *csfloat.ActiveListing) bool {
+			return listing.Seller.SteamID == sellerId
+		})
+	}
+	rarity, err := queryUint(request, "rarity", 0)
+	if err != nil {
+		return nil, err
+	}
+	if rarity > 0 {
+		filters = append(filters, func(listing *csfloat.ActiveListing) bool {
+			return uint(listing.Item.Rarity) == rarity
+		})
+	}
+
+	listings := server.listed(func(listing *csfloat.ActiveListing) bool {
+		if listing.Private {
+			return false
+		}
+		for _, filter := range filters {
+			if !filter(listing) {
+				return false
+			}
+		}
+		return true
+	})
+	sortListings(listings, csfloat.SortListingsBy(query.Get("sort_by")))
+
+	data, cursor, err := cursorPage(request, listings, limit)
+	if err != nil {
+		return nil, err
+	}
+	response := map[string]any{"data": data}
+	if cursor != "" {
+		response["cursor"] = cursor
+	}
+	return response, nil
+}
+
+func sortListings(listings []csfloat.ActiveListing, sortBy csfloat.SortListingsBy) {
+	switch sortBy {
+	case csfloat.LowestPrice:
+		slices.SortStableFunc(listings, func(a, b csfloat.ActiveListing) int { return a.Price - b.Price })
+	case csfloat.HighestPrice:
+		slices.SortStableFunc(listings, func(a, b csfloat.ActiveListing) int { return b.Price - a.Price })
+	}
+}
+
+// cursorPage returns the page at the request's cursor and the cursor of the
+// next page, which is empty on the last page. The cursor is the offset of the
+// next page. CSFloat's cursors are opaque, so clients must not rely on this.
+func cursorPage[T any](request *http.Request, items []T, limit uint) ([]T, string, *apiError) {
+	var offset uint
+	if cursor := request.URL.Query().Get("cursor"); cursor != "" {
+		parsed, err := strconv.ParseUint(cursor, 10, 64)
+		if err != nil {
+			return nil, "", errorf(http.StatusBadRequest, 0, "invalid cursor")
+		}
+		offset = uint(parsed)
+	}
+	start := min(int(offset), len(items))
+	end := min(start+int(limit), len(items))
+	if end < len(items) {
+		return items[start:end], strconv.Itoa(end), nil
+	}
+	return items[start:end], "", nil
+}
+
+func (server *Server) handleListing(request *http.Request) (any, *apiError) {
+	listing, ok := server.listings[request.PathValue("id")]
+	if !ok {
+		return nil, errNotFound("listing")
+	}
+	return listing, nil
+}
+
+// ownListing returns the listing, if it is listed in our stall.
+func (server *Server) ownListing(id string) (*csfloat.ActiveListing, *apiError) {
+	listing, ok := server.listings[id]
+	if !ok || listing.Seller.SteamID != server.me.SteamId {
+		return nil, errNotFound("listing")
+	}
+	return listing, nil
+}
+
+func (server *Server) list(payload csfloat.ListRequest) (*csfloat.ActiveListing, *apiError) {
+	index := slices.IndexFunc(server.inventory, func(item *csfloat.InventoryItem) bool {
+		return item.ID == payload.AssetId
+	})
+	if index == -1 {
+		return nil, errorf(http.StatusBadRequest, 0, "item %s is not in your inventory", payload.AssetId)
+	}
+	item := server.inventory[index]
+	if item.ListingID != "" {
+		return nil, errorf(http.StatusBadRequest, 0, "item %s is already listed", payload.AssetId)
+	}
+
+	listingType := payload.AuctionType
+	if listingType == "" {
+		listingType = csfloat.BuyNow
+	}
+	listing := &csfloat.ActiveListing{
+		ID:          server.id(),
+		CreatedAt:   time.Now(),
+		Item:        item.Item,
+		Reference:   item.Reference,
+		Type:        listingType,
+		State:       csfloat.ListingStateListed,
+		Seller:      csfloat.Seller{SteamID: server.me.SteamId},
+		Description: payload.Description,
+		Private:     payload.Private,
+	}
+	if payload.BuyNowRequest != nil {
+		listing.Price = int(payload.BuyNowRequest.Price)
+	}
+	if payload.AuctionRequest != nil && listingType == csfloat.Auction {
+		listing.Price = int(payload.AuctionRequest.ReservePrice)
+		listing.AuctionDetails = &csfloat.AuctionDetails{
+			ReservePrice: payload.AuctionRequest.ReservePrice,
+			ExpiresAt:    time.Now().AddDate(0, 0, int(max(payload.AuctionRequest.DurationDays, 1))),
+			MinNextBid:   payload.AuctionRequest.ReservePrice,
+		}
+	}
+	if listing.Price <= 0 {
+		return nil, errorf(http.StatusBadRequest, 0, "invalid price")
+	}
+
+	item.ListingID = listing.ID
+	server.listings[listing.ID] = listing
+	return listing, nil
+}
+
+func (server *Server) handleList(request *http.Request) (any, *apiError) {
+	var payload csfloat.ListRequest
+	if err := decode(request, &payload); err != nil {
+		return nil, err
+	}
+	return server.list(payload)
+}
+
+func (server *Server) handleBulkList(request *http.Request) (any, *apiError) {
+	var payload csfloat.BulkListRequest
+	if err := decode(request, &payload); err != nil {
+		return nil, err
+	}
+
+	listings := make([]*csfloat.ActiveListing, 0, len(payload.Items))
+	for _, item := range payload.Items {
+		listing, err := server.list(item)
+		if err != nil {
+			return nil, err
+		}
+		listings = append(listings, listing)
+	}
+	return map[string]any{"data": listings}, nil
+}
+
+func (server *Server) unlist(id string) *apiError {
+	listing, err := server.ownListing(id)
+	if err != nil {
+		return err
+	}
+	if listing.State != csfloat.ListingStateListed {
+		return errorf(http.StatusBadRequest, 0, "listing %s is not listed", id)
+	}
+
+	delete(server.listings, id)
+	for _, item := range server.inventory {
+		if item.ListingID == id {
+			item.ListingID = ""
+		}
+	}
+	return nil
+}
+
+func (server *Server) handleUnlist(request *http.Request) (any, *apiError) {
+	if err := server.unlist(request.PathValue("id")); err != nil {
+		return nil, err
+	}
+	return map[string]any{"message": "successfully delisted item"}, nil
+}
+
+func (server *Server) handleBulkUnlist(request *http.Request) (any, *apiError) {
+	var payload struct {
+		ContractIds []string `json:"contract_ids"`
+	}
+	if err := decode(request, &payload); err != nil {
+		return nil, err
+	}
+	for _, id := range payload.ContractIds {
+		if err := server.unlist(id); err != nil {
+			return nil, err
+		}
+	}
+	return map[string]any{"message": "successfully delisted items"}, nil
+}
+
+func (server *Server) handleUpdateListing(request *http.Request) (any, *apiError) {
+	listing, err := server.ownListing(request.PathValue("id"))
+	if err != nil {
+		return nil, err
+	}
+
+	var payload struct {
+		Price            *int    `json:"price"`
+		Description      *string `json:"description"`
+		Private          *bool   `json:"private"`
+		MaxOfferDiscount *uint   `json:"max_offer_discount"`
+	}
+	if err := decode(request, &payload); err != nil {
+		return nil, err
+	}
+	if payload.Price != nil {
+		if *payload.Price <= 0 {
+			return nil, errorf(http.StatusBadRequest, 0, "invalid price")
+		}
+		listing.Price = *payload.Price
+	}
+	if payload.Description != nil {
+		listing.Description = *payload.Description
+	}
+	if payload.Private != nil {
+		listing.Private = *payload.Private
+	}
+	if payload.MaxOfferDiscount != nil {
+		listing.MaxOfferDiscount = *payload.MaxOfferDiscount
+	}
+	return listing, nil
+}
+
+func (server *Server) handleBuy(request *http.Request) (any, *apiError) {
+	var payload csfloat.BuyRequestPayload
+	if err := decode(request, &payload); err != nil {
+		return nil, err
+	}
+
+	var total uint
+	for _, id := range payload.ContractIds {
+		listing, ok := server.listings[id]
+		if !ok || listing.State != csfloat.ListingStateListed {
+			return nil, AlreadySold.apiError()
+		}
+		total += uint(listing.Price)
+	}
+	if total != payload.TotalPrice {
+		return nil, PriceChanged.apiError()
+	}
+	if total > server.me.Balance {
+		return nil, errorf(http.StatusBadRequest, 0, "insufficient balance")
+	}
+
+	now := time.Now()
+	server.me.Balance -= total
+	for _, id := range payload.ContractIds {
+		listing := server.listings[id]
+		listing.State = ListingStateSold
+		server.trades = append(server.trades, csfloat.Trade{
+			ID:      server.id(),
+			BuyerId: server.me.SteamId,
+			Contract: csfloat.Contract{
+				ID:        listing.ID,
+				CreatedAt: listing.CreatedAt,
+				Price:     listing.Price,
+				Item:      listing.Item,
+				Reference: listing.Reference,
+				Type:      listing.Type,
+				State:     listing.State,
+			},
+			CreatedAt:        now,
+			State:            csfloat.Queued,
+			VerificationMode: csfloat.Inventory,
+		})
+		server.transactions = append(server.transactions, csfloat.Transaction{
+			ID:            server.id(),
+			CreatedAt:     now,
+			Type:          csfloat.TransactionTypeContractPurchased,
+			Details:       csfloat.TransactionDetails{ContractID: listing.ID},
+			BalanceOffset: -listing.Price,
+		})
+	}
+	return map[string]any{"message": "all listings purchased"}, nil
+}
+
+func (server *Server) handleSimilar(request *http.Request) (any, *apiError) {
+	listing, ok := server.listings[request.PathValue("id")]
+	if !ok {
+		return nil, errNotFound("listing")
+	}
+	similar := server.listed(func(other *csfloat.ActiveListing) bool {
+		return other.ID != listing.ID && other.Item.MarketHashName == listing.Item.MarketHashName
+	})
+	if similar == nil {
+		similar = []csfloat.ActiveListing{}
+	}
+	return similar, nil
+}
+
+func (server *Server) handleWatch(request *http.Request) (any, *apiError) {
+	listing, ok := server.listings[request.PathValue("id")]
+	if !ok {
+		return nil, errNotFound("listing")
+	}
+	if _, watched := server.watchlist[listing.ID]; !watched {
+		server.watchlist[listing.ID] = struct{}{}
+		listing.Watchers++
+	}
+	return map[string]any{"message": "added to watchlist"}, nil
+}
+
+func (server *Server) handleUnwatch(request *http.Request) (any, *apiError) {
+	listing, ok := server.listings[request.PathValue("id")]
+	if !ok {
+		return nil, errNotFound("listing")
+	}
+	if _, watched := server.watchlist[listing.ID]; watched {
+		delete(server.watchlist, listing.ID)
+		listing.Watchers--
+	}
+	return map[string]any{"message": "removed from watchlist"}, nil
+}
+
+func (server *Server) handleCreateBuyOrder(request *http.Request) (any, *apiError) {
+	var payload struct {
+		MarketHashName string `json:"market_hash_name"`
+		Expression     string `json:"expression"`
+		MaxPrice       uint   `json:"max_price"`
+		Quantity       uint   `json:"quantity"`
+	}
+	if err := decode(request, &payload); err != nil {
+		return nil, err
+	}
+	if (payload.MarketHashName == "") == (payload.Expression == "") {
+		return nil, errorf(http.StatusBadRequest, 0, "either market_hash_name or expression is required")
+	}
+	if payload.MaxPrice == 0 || payload.Quantity == 0 {
+		return nil, errorf(http.StatusBadRequest, 0, "invalid price or quantity")
+	}
+
+	order := &csfloat.ItemBuyOrder{
+		ID:             server.id(),
+		MarketHashName: payload.MarketHashName,
+		Expression:     payload.Expression,
+		Quantity:       payload.Quantity,
+		Price:          payload.MaxPrice,
+	}
+	server.buyOrders = append(server.buyOrders, order)
+	server.myBuyOrders[order.ID] = struct{}{}
+	return order, nil
+}
+
+// myBuyOrder returns one of our buy orders.
+func (server *Server) myBuyOrder(id string) (*csfloat.ItemBuyOrder, *apiError) {
+	if _, ok := server.myBuyOrders[id]; !ok {
+		return nil, errNotFound("buy order")
+	}
+	index := slices.IndexFunc(server.buyOrders, func(order *csfloat.ItemBuyOrder) bool {
+		return order.ID == id
+	})
+	return server.buyOrders[index], nil
+}
+
+func (server *Server) handleMyBuyOrders(request *http.Request) (any, *apiError) {
+	pageIndex, err := queryUint(request, "page", 0)
+	if err != nil {
+		return nil, err
+	}
+	limit, err := queryUint(request, "limit", 100)
+	if err != nil {
+		return nil, err
+	}
+
+	orders := []csfloat.ItemBuyOrder{}
+	for _, order := range slices.Backward(server.buyOrders) {
+		if _, ok := server.myBuyOrders[order.ID]; ok {
+			orders = append(orders, *order)
+		}
+	}
+	return map[string]any{
+		"orders": page(orders, pageIndex, limit),
+		"count":  len(orders),
+	}, nil
+}
+
+func (server *Server) handleUpdateBuyOrder(request *http.Request) (any, *apiError) {
+	order, err := server.myBuyOrder(request.PathValue("id"))
+	if err != nil {
+		return nil, err
+	}
+	var payload struct {
+		MaxPrice uint `json:"max_price"`
+		Quantity uint `json:"quantity"`
+	}
+	if err := decode(request, &payload); err != nil {
+		return nil, err
+	}
+	if payload.MaxPrice == 0 && payload.Quantity == 0 {
+		return nil, errorf(http.StatusBadRequest, 0, "nothing to update")
+	}
+	if payload.MaxPrice != 0 {
+		order.Price = payload.MaxPrice
+	}
+	if payload.Quantity != 0 {
+		order.Quantity = payload.Quantity
+	}
+	return order, nil
+}
+
+func (server *Server) handleDeleteBuyOrder(request *http.Request) (any, *apiError) {
+	id := request.PathValue("id")
+	if _, err := server.myBuyOrder(id); err != nil {
+		return nil, err
+	}
+	server.deleteBuyOrder(id)
+	return map[string]any{"message": "successfully removed the order"}, nil
+}
+
+func (server *Server) handleBulkDeleteBuyOrders(request *http.Request) (any, *apiError) {
+	var payload struct {
+		IDs []string `json:"ids"`
+	}
+	if err := decode(request, &payload); err != nil {
+		return nil, err
+	}
+	if len(payload.IDs) == 0 {
+		return nil, errorf(http.StatusBadRequest, 0, "no buy orders given")
+	}
+	// Nothing is deleted if any of the orders is unknown.
+	for _, id := range payload.IDs {
+		if _, err := server.myBuyOrder(id); err != nil {
+			return nil, err
+		}
+	}
+	for _, id := range payload.IDs {
+		server.deleteBuyOrder(id)
+	}
+	return map[string]any{"message": "successfully removed the orders"}, nil
+}
+
+func (server *Server) deleteBuyOrder(id string) {
+	server.buyOrders = slices.DeleteFunc(server.buyOrders, func(order *csfloat.ItemBuyOrder) bool {
+		return order.ID == id
+	})
+	delete(server.myBuyOrders, id)
+}
+
+// buyOrdersFor returns the simple buy orders for the given item, highest
+// price first.
+func (server *Server) buyOrdersFor(marketHashName string, limit uint) []csfloat.ItemBuyOrder {
+	orders := []csfloat.ItemBuyOrder{}
+	for _, order := range server.buyOrders {
+		if order.MarketHashName == marketHashName {
+			orders = append(orders, *order)
+		}
+	}
+	slices.SortStableFunc(orders, func(a, b csfloat.ItemBuyOrder) int {
+		return int(b.Price) - int(a.Price)
+	})
+	return page(orders, 0, limit)
+}
+
+func (server *Server) handleItemBuyOrders(request *http.Request) (any, *apiError) {
+	limit, err := queryUint(request, "limit", 3)
+	if err != nil {
+		return nil, err
+	}
+	return server.buyOrdersFor(request.URL.Query().Get("market_hash_name"), limit), nil
+}
+
+func (server *Server) handleSimilarBuyOrders(request *http.Request) (any, *apiError) {
+	limit, err := queryUint(request, "limit", 3)
+	if err != nil {
+		return nil, err
+	}
+	var payload struct {
+		MarketHashName string `json:"market_hash_name"`
+	}
+	if err := decode(request, &payload); err != nil {
+		return nil, err
+	}
+	return map[string]any{"data": server.buyOrdersFor(payload.MarketHashName, limit)}, nil
+}
+
+func (server *Server) handleListingBuyOrders(request *http.Request) (any, *apiError) {
+	limit, err := queryUint(request, "limit", 10)
+	if err != nil {
+		return nil, err
+	}
+	listing, ok := server.listings[request.PathValue("id")]
+	if !ok {
+		return nil, errNotFound("listing")
+	}
+	return server.buyOrdersFor(listing.Item.MarketHashName, limit), nil
+}
+
+// bid is a bid on an auction, including the max price, which CSFloat only
+// reveals to the bidder.
+type bid struct {
+	csfloat.Bid
+	maxPrice uint
+	mine     bool
+}
+
+// bidIncrement is the minimum amount by which a bid has to be raised.
+func bidIncrement(price uint) uint {
+	return max(price/100, 1)
+}
+
+// placeBid implements proxy bidding: the top bid is only raised as far as
+// required to beat the second highest max price.
+func (server *Server) placeBid(listingId string, maxPrice uint, mine bool) (*bid, *apiError) {
+	listing, ok := server.listings[listingId]
+	if !ok {
+		return nil, errNotFound("listing")
+	}
+	if listing.Type != csfloat.Auction || listing.AuctionDetails == nil {
+		return nil, errorf(http.StatusBadRequest, 0, "listing is not an auction")
+	}
+	details := listing.AuctionDetails
+	if listing.State != csfloat.ListingStateListed || details.Ended() {
+		return nil, errorf(http.StatusBadRequest, 0, "auction has ended")
+	}
+	if mine && listing.Seller.SteamID == server.me.SteamId {
+		return nil, errorf(http.StatusBadRequest, 0, "you can't bid on your own auction")
+	}
+	if maxPrice < details.MinNextBid {
+		return nil, errorf(http.StatusBadRequest, 0, "bid must be at least %d", details.MinNextBid)
+	}
+
+	placed := &bid{
+		Bid: csfloat.Bid{
+			ID:                server.id(),
+			CreatedAt:         time.Now(),
+			Price:             details.MinNextBid,
+			ContractID:        listing.ID,
+			ObfuscatedBuyerID: server.id(),
+		},
+		maxPrice: maxPrice,
+		mine:     mine,
+	}
+	var top *bid
+	for _, other := range server.bids {
+		if other.ContractID == listing.ID && (top == nil || other.maxPrice > top.maxPrice) {
+			top = other
+		}
+	}
+	switch {
+	case top == nil:
+		top = placed
+	case placed.maxPrice > top.maxPrice:
+		placed.Price = min(placed.maxPrice, top.maxPrice+bidIncrement(top.maxPrice))
+		top = placed
+	default:
+		placed.Price = placed.maxPrice
+		top.Price = min(top.maxPrice, placed.maxPrice+bidIncrement(placed.maxPrice))
+	}
+	server.bids = append(server.bids, placed)
+
+	topBid := top.Bid
+	details.TopBid = &topBid
+	details.BidCount++
+	details.MinNextBid = top.Price + bidIncrement(top.Price)
+	listing.Price = int(top.Price)
+	return placed, nil
+}
+
+func (server *Server) handlePlaceBid(request *http.Request) (any, *apiError) {
+	var payload struct {
+		MaxPrice uint `json:"max_price"`
+	}
+	if err := decode(request, &payload); err != nil {
+		return nil, err
+	}
+	placed, err := server.placeBid(request.PathValue("id"), payload.MaxPrice, true)
+	if err != nil {
+		return nil, err
+	}
+	return placed.Bid, nil
+}
+
+func (server *Server) handleBids(request *http.Request) (any, *apiError) {
+	listing, ok := server.listings[request.PathValue("id")]
+	if !ok {
+		return nil, errNotFound("listing")
+	}
+	bids := []csfloat.Bid{}
+	for _, bid := range slices.Backward(server.bids) {
+		if bid.ContractID == listing.ID {
+			bids = append(bids, bid.Bid)
+		}
+	}
+	return bids, nil
+}
+
+func (server *Server) handleMyBids(*http.Request) (any, *apiError) {
+	bids := []csfloat.MyBid{}
+	for _, bid := range slices.Backward(server.bids) {
+		if bid.mine {
+			bids = append(bids, csfloat.MyBid{
+				Bid:      bid.Bid,
+				MaxPrice: bid.maxPrice,
+				Contract: *server.listings[bid.ContractID],
+			})
+		}
+	}
+	return bids, nil
+}
+
+// withContract fills in the offer's listing.
+func (server *Server) withContract(offer csfloat.Offer) csfloat.Offer {
+	if listing, ok := server.listings[offer.ContractID]; ok {
+		offer.Contract = cloneListing(*listing)
+	}
+	return offer
+}
+
+// receiverOf returns the steam ID of the user who has to respond to the offer.
+func receiverOf(offer *csfloat.Offer) string {
+	if offer.Type == csfloat.SellerOffer {
+		return offer.BuyerID
+	}
+	return offer.SellerID
+}
+
+// activeOffer returns the offer, if it is active and we are involved.
+func (server *Server) activeOffer(id string) (*csfloat.Offer, *apiError) {
+	index := slices.IndexFunc(server.offers, func(offer *csfloat.Offer) bool {
+		return offer.ID == id
+	})
+	if index == -1 {
+		return nil, errNotFound("offer")
+	}
+	offer := server.offers[index]
+	if offer.BuyerID != server.me.SteamId && offer.SellerID != server.me.SteamId {
+		return nil, errNotFound("offer")
+	}
+	if offer.State != csfloat.OfferActive {
+		return nil, errorf(http.StatusBadRequest, 0, "offer is %s", offer.State)
+	}
+	return offer, nil
+}
+
+func (server *Server) handleSendOffer(request *http.Request) (any, *apiError) {
+	var payload csfloat.SendOfferRequest
+	if err := decode(request, &payload); err != nil {
+		return nil, err
+	}
+	listing, ok := server.listings[payload.ContractID]
+	if !ok || listing.State != csfloat.ListingStateListed {
+		return nil, errNotFound("listing")
+	}
+	if listing.Type != csfloat.BuyNow {
+		return nil, errorf(http.StatusBadRequest, 0, "offers are only possible on buy now listings")
+	}
+	if listing.Seller.SteamID == server.me.SteamId {
+		return nil, errorf(http.StatusBadRequest, 0, "you can't make offers on your own listing")
+	}
+	if payload.Price == 0 || payload.Price >= uint(listing.Price) {
+		return nil, errorf(http.StatusBadRequest, 0, "offer must be below the listing price")
+	}
+
+	now := time.Now()
+	offer := &csfloat.Offer{
+		ID:         server.id(),
+		CreatedAt:  now,
+		ExpiresAt:  now.Add(24 * time.Hour),
+		Type:       csfloat.BuyerOffer,
+		State:      csfloat.OfferActive,
+		BuyerID:    server.me.SteamId,
+		SellerID:   listing.Seller.SteamID,
+		ContractID: listing.ID,
+		Price:      payload.Price,
+	}
+	server.offers = append(server.offers, offer)
+	return server.withContract(*offer), nil
+}
+
+func (server *Server) handleOffers(request *http.Request) (any, *apiError) {
+	pageIndex, err := queryUint(request, "page", 0)
+	if err != nil {
+		return nil, err
+	}
+	limit, err := queryUint(request, "limit", 100)
+	if err != nil {
+		return nil, err
+	}
+	direction := csfloat.OfferDirection(request.URL.Query().Get("type"))
+	if direction != csfloat.Incoming && direction != csfloat.Outgoing {
+		return nil, errorf(http.StatusBadRequest, 0, "invalid type")
+	}
+	var states []string
+	if state := request.URL.Query().Get("state"); state != "" {
+		states = strings.Split(state, ",")
+	}
+
+	offers := []csfloat.Offer{}
+	for _, offer := range slices.Backward(server.offers) {
+		incoming := receiverOf(offer) == server.me.SteamId
+		outgoing := offer.SenderID() == server.me.SteamId
+		if (direction == csfloat.Incoming && !incoming) || (direction == csfloat.Outgoing && !outgoing) {
+			continue
+		}
+		if len(states) > 0 && !slices.Contains(states, string(offer.State)) {
+			continue
+		}
+		offers = append(offers, server.withContract(*offer))
+	}
+	return map[string]any{
+		"offers": page(offers, pageIndex, limit),
+		"count":  len(offers),
+	}, nil
+}
+
+func (server *Server) handleAcceptOffer(request *http.Request) (any, *apiError) {
+	offer, err := server.activeOffer(request.PathValue("id"))
+	if err != nil {
+		return nil, err
+	}
+	if receiverOf(offer) != server.me.SteamId {
+		return nil, errorf(http.StatusBadRequest, 0, "you can't accept your own offer")
+	}
+	listing, ok := server.listings[offer.ContractID]
+	if !ok || listing.State != csfloat.ListingStateListed {
+		offer.State = csfloat.OfferCancelled
+		return nil, AlreadySold.apiError()
+	}
+	if offer.BuyerID == server.me.SteamId {
+		if offer.Price > server.me.Balance {
+			return nil, errorf(http.StatusBadRequest, 0, "insufficient balance")
+		}
+		server.me.Balance -= offer.Price
+	}
+
+	offer.State = csfloat.OfferAccepted
+	for _, other := range server.offers {
+		if other.ContractID == offer.ContractID && other.State == csfloat.OfferActive {
+			other.State = csfloat.OfferCancelled
+		}
+	}
+	listing.State = ListingStateSold
+	server.trades = append(server.trades, csfloat.Trade{
+		ID:      server.id(),
+		BuyerId: offer.BuyerID,
+		Contract: csfloat.Contract{
+			ID:        listing.ID,
+			CreatedAt: listing.CreatedAt,
+			Price:     int(offer.Price),
+			Item:      listing.Item,
+			Reference: listing.Reference,
+			Type:      listing.Type,
+			State:     listing.State,
+		},
+		CreatedAt:        time.Now(),
+		State:            csfloat.Queued,
+		VerificationMode: csfloat.Inventory,
+	})
+	return server.withContract(*offer), nil
+}
+
+func (server *Server) handleDeclineOffer(request *http.Request) (any, *apiError) {
+	offer, err := server.activeOffer(request.PathValue("id"))
+	if err != nil {
+		return nil, err
+	}
+	if receiverOf(offer) == server.me.SteamId {
+		offer.State = csfloat.OfferDeclined
+	} else {
+		offer.State = csfloat.OfferCancelled
+	}
+	return server.withContract(*offer), nil
+}
+
+func (server *Server) handleCounterOffer(request *http.Request) (any, *apiError) {
+	offer, err := server.activeOffer(request.PathValue("id"))
+	if err != nil {
+		return nil, err
+	}
+	if receiverOf(offer) != server.me.SteamId {
+		return nil, errorf(http.StatusBadRequest, 0, "you can't counter your own offer")
+	}
+	var payload struct {
+		Price uint `json:"price"`
+	}
+	if err := decode(request, &payload); err != nil {
+		return nil, err
+	}
+	if payload.Price == 0 || payload.Price == offer.Price {
+		return nil, errorf(http.StatusBadRequest, 0, "invalid price")
+	}
+
+	offer.State = csfloat.OfferCountered
+	counterType := csfloat.SellerOffer
+	if offer.Type == csfloat.SellerOffer {
+		counterType = csfloat.BuyerOffer
+	}
+	now := time.Now()
+	counter := &csfloat.Offer{
+		ID:         server.id(),
+		CreatedAt:  now,
+		ExpiresAt:  now.Add(24 * time.Hour),
+		Type:       counterType,
+		State:      csfloat.OfferActive,
+		BuyerID:    offer.BuyerID,
+		SellerID:   offer.SellerID,
+		ContractID: offer.ContractID,
+		Price:      payload.Price,
+		ParentID:   offer.ID,
+	}
+	server.offers = append(server.offers, counter)
+	return server.withContract(*counter), nil
+}
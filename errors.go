@@ -0,0 +1,168 @@
+package csfloat
+
+import (
+	json "encoding/json/v2"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// ErrorCodeOverpricedRedquireKYC shares its code with ErrorCodeAlreadySold.
+	// Use errors.Is with ErrOverpricedRequiresKYC to tell them apart.
+	ErrorCodeOverpricedRedquireKYC = 4
+	// ErrorCodeAlreadySold shares its code with ErrorCodeOverpricedRedquireKYC.
+	// Use errors.Is with ErrAlreadySold to tell them apart.
+	ErrorCodeAlreadySold = 4
+	// ErrorCodeInvalidPurchaseState is thrown along HTTP status code 422. It
+	// is unclear when exactly, but it seems similar to AlreadySold. It might
+	// be unlisted.
+	ErrorCodeInvalidPurchaseState = 6
+	ErrorCodePriceChanged         = 15
+	// ErrorCodeSalesHistoryNotAvailable implies that the history for a certain
+	// item was disabled. This is done for cases for example.
+	ErrorCodeSalesHistoryNotAvailable = 200
+)
+
+// These sentinel errors can be used with errors.Is on any error returned by
+// the API. Errors returned due to an error response by CSFloat can also be
+// inspected with errors.As and *Error.
+var (
+	ErrAlreadySold              = errors.New("already sold")
+	ErrOverpricedRequiresKYC    = errors.New("overpriced, requires KYC")
+	ErrInvalidPurchaseState     = errors.New("invalid purchase state")
+	ErrPriceChanged             = errors.New("price changed")
+	ErrSalesHistoryNotAvailable = errors.New("sales history not available")
+	// ErrRatelimited is matched by HTTP 429 responses and by *RatelimitError.
+	ErrRatelimited = errors.New("ratelimited")
+	// ErrUnauthorized is matched by HTTP 401 and 403 responses, for example
+	// if the API key is invalid.
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrNotFound     = errors.New("not found")
+)
+
+// Error is the generic error format used by CSFloat.
+type Error struct {
+	HttpStatus uint   `json:"-"`
+	Code       uint   `json:"code"`
+	Message    string `json:"message"`
+}
+
+func (err *Error) Error() string {
+	return fmt.Sprintf("csfloat error %d (HTTP %d): %s", err.Code, err.HttpStatus, err.Message)
+}
+
+// Is allows matching the error against the sentinel errors, such as
+// ErrAlreadySold, using errors.Is.
+func (err *Error) Is(target error) bool {
+	switch target {
+	case ErrRatelimited:
+		return err.HttpStatus == http.StatusTooManyRequests
+	case ErrUnauthorized:
+		return err.HttpStatus == http.StatusUnauthorized || err.HttpStatus == http.StatusForbidden
+	case ErrNotFound:
+		return err.HttpStatus == http.StatusNotFound
+	}
+	return target != nil && err.codeError() == target
+}
+
+// codeError maps the error code to a sentinel error. Code 4 is used for
+// multiple errors, so we disambiguate by the HTTP status, falling back to the
+// message for any other status.
+func (err *Error) codeError() error {
+	switch err.Code {
+	case 4:
+		switch err.HttpStatus {
+		case http.StatusUnprocessableEntity:
+			return ErrAlreadySold
+		case http.StatusBadRequest:
+			return ErrOverpricedRequiresKYC
+		}
+		message := strings.ToLower(err.Message)
+		if strings.Contains(message, "kyc") || strings.Contains(message, "verif") {
+			return ErrOverpricedRequiresKYC
+		}
+		return ErrAlreadySold
+	case ErrorCodeInvalidPurchaseState:
+		return ErrInvalidPurchaseState
+	case ErrorCodePriceChanged:
+		return ErrPriceChanged
+	case ErrorCodeSalesHistoryNotAvailable:
+		return ErrSalesHistoryNotAvailable
+	}
+	return nil
+}
+
+// Is makes RatelimitError match ErrRatelimited.
+func (err *RatelimitError) Is(target error) bool {
+	return target == ErrRatelimited
+}
+
+func errorFrom(response *http.Response) (Error, error) {
+	var csfloatError Error
+	csfloatError.HttpStatus = uint(response.StatusCode)
+	return csfloatError, json.UnmarshalRead(response.Body, &csfloatError)
+}
+
+// UnknownErrorCode is an error code we have no constant for, but which was
+// returned by CSFloat at runtime.
+type UnknownErrorCode struct {
+	Code uint
+	// HttpStatus and Message are taken from the last occurrence.
+	HttpStatus uint
+	Message    string
+	Count      uint
+	FirstSeen  time.Time
+	LastSeen   time.Time
+}
+
+// unknownErrorCodes records all error codes without a known meaning.
+type unknownErrorCodes struct {
+	lock  sync.Mutex
+	codes map[uint]*UnknownErrorCode
+}
+
+func (registry *unknownErrorCodes) record(err *Error) {
+	// Code 0 means there is no code, for example for plain HTTP errors.
+	if err.Code == 0 || err.codeError() != nil {
+		return
+	}
+
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+
+	now := time.Now()
+	if registry.codes == nil {
+		registry.codes = make(map[uint]*UnknownErrorCode)
+	}
+	entry, ok := registry.codes[err.Code]
+	if !ok {
+		entry = &UnknownErrorCode{Code: err.Code, FirstSeen: now}
+		registry.codes[err.Code] = entry
+	}
+	entry.HttpStatus = err.HttpStatus
+	entry.Message = err.Message
+	entry.Count++
+	entry.LastSeen = now
+}
+
+// UnknownErrorCodes returns all error codes returned by CSFloat so far that
+// have no known meaning, sorted by code. Please report them, so constants can
+// be added.
+func (api *API) UnknownErrorCodes() []UnknownErrorCode {
+	api.unknownErrorCodes.lock.Lock()
+	defer api.unknownErrorCodes.lock.Unlock()
+
+	codes := make([]UnknownErrorCode, 0, len(api.unknownErrorCodes.codes))
+	for _, entry := range api.unknownErrorCodes.codes {
+		codes = append(codes, *entry)
+	}
+	slices.SortFunc(codes, func(a, b UnknownErrorCode) int {
+		return int(a.Code) - int(b.Code)
+	})
+	return codes
+}
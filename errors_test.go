@@ -0,0 +1,132 @@
+package csfloat_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	csfloat "github.com/Bios-Marcel/csfloat_go"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ErrorSentinels(t *testing.T) {
+	type testCase struct {
+		err      csfloat.Error
+		expected error
+	}
+
+	testCases := []testCase{
+		{
+			err:      csfloat.Error{HttpStatus: 422, Code: 4, Message: "listing is no longer available"},
+			expected: csfloat.ErrAlreadySold,
+		},
+		{
+			err:      csfloat.Error{HttpStatus: 400, Code: 4, Message: "you must complete KYC verification to list at this price"},
+			expected: csfloat.ErrOverpricedRequiresKYC,
+		},
+		{
+			err:      csfloat.Error{HttpStatus: 422, Code: 4},
+			expected: csfloat.ErrAlreadySold,
+		},
+		{
+			err:      csfloat.Error{HttpStatus: 400, Code: 4},
+			expected: csfloat.ErrOverpricedRequiresKYC,
+		},
+		{
+			err:      csfloat.Error{HttpStatus: 422, Code: 4, Message: "verification pending"},
+			expected: csfloat.ErrAlreadySold,
+		},
+		{
+			err:      csfloat.Error{HttpStatus: 500, Code: 4, Message: "KYC required"},
+			expected: csfloat.ErrOverpricedRequiresKYC,
+		},
+		{
+			err:      csfloat.Error{HttpStatus: 500, Code: 4, Message: "sold"},
+			expected: csfloat.ErrAlreadySold,
+		},
+		{
+			err:      csfloat.Error{HttpStatus: 422, Code: 6},
+			expected: csfloat.ErrInvalidPurchaseState,
+		},
+		{
+			err:      csfloat.Error{HttpStatus: 400, Code: 15},
+			expected: csfloat.ErrPriceChanged,
+		},
+		{
+			err:      csfloat.Error{HttpStatus: 429, Code: 0},
+			expected: csfloat.ErrRatelimited,
+		},
+		{
+			err:      csfloat.Error{HttpStatus: 401, Code: 0},
+			expected: csfloat.ErrUnauthorized,
+		},
+		{
+			err:      csfloat.Error{HttpStatus: 403, Code: 0},
+			expected: csfloat.ErrUnauthorized,
+		},
+		{
+			err:      csfloat.Error{HttpStatus: 404, Code: 0},
+			expected: csfloat.ErrNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		assert.ErrorIs(t, &tc.err, tc.expected, tc.err.Error())
+	}
+
+	assert.NotErrorIs(t, &csfloat.Error{HttpStatus: 422, Code: 4}, csfloat.ErrOverpricedRequiresKYC)
+	assert.NotErrorIs(t, &csfloat.Error{HttpStatus: 400, Code: 4}, csfloat.ErrAlreadySold)
+	assert.ErrorIs(t, &csfloat.RatelimitError{}, csfloat.ErrRatelimited)
+}
+
+func Test_ErrorWrapped(t *testing.T) {
+	ass := assert.New(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/listings/buy", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Ratelimit-Limit", "100")
+		w.Header().Set("X-Ratelimit-Remaining", "99")
+		w.Header().Set("X-Ratelimit-Reset", "0")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"code":15,"message":"price changed"}`))
+	})
+	mux.HandleFunc("GET /api/v1/me", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Ratelimit-Limit", "100")
+		w.Header().Set("X-Ratelimit-Remaining", "99")
+		w.Header().Set("X-Ratelimit-Reset", "0")
+		w.WriteHeader(http.StatusTeapot)
+		w.Write([]byte(`{"code":1337,"message":"i'm a teapot"}`))
+	})
+	api := newTestAPI(t, mux)
+
+	response, err := api.Buy(csfloat.BuyRequestPayload{ContractIds: []string{"1"}, TotalPrice: 1})
+	ass.ErrorIs(err, csfloat.ErrPriceChanged)
+	var csfloatErr *csfloat.Error
+	if ass.True(errors.As(err, &csfloatErr)) {
+		ass.Equal(uint(http.StatusBadRequest), csfloatErr.HttpStatus)
+		ass.Equal(csfloatErr, response.Error)
+	}
+	ass.Empty(api.UnknownErrorCodes())
+
+	for range 2 {
+		_, err = api.Me()
+		ass.Error(err)
+	}
+	unknown := api.UnknownErrorCodes()
+	if ass.Len(unknown, 1) {
+		ass.Equal(uint(1337), unknown[0].Code)
+		ass.Equal(uint(2), unknown[0].Count)
+		ass.Equal("i'm a teapot", unknown[0].Message)
+	}
+}
+
+func Test_UnknownErrorCodesWithoutCode(t *testing.T) {
+	api := newTestAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"message":"internal server error"}`))
+	}))
+
+	_, err := api.Me()
+	assert.Error(t, err)
+	assert.Empty(t, api.UnknownErrorCodes())
+}
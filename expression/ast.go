@@ -0,0 +1,168 @@
+// Package expression implements the expression language of advanced buy
+// orders, see csfloat.ItemBuyOrder.Expression.
+//
+// Expressions can be parsed via Parse, built via the Field methods and Where,
+// printed via String and evaluated locally against an item via Expr.Matches.
+// For example:
+//
+//	DefIndex == 7 and PaintIndex == 282 and FloatValue < 0.16
+package expression
+
+import (
+	"strconv"
+	"strings"
+
+	csfloat "github.com/Bios-Marcel/csfloat_go"
+)
+
+// Expr is a node of an expression.
+type Expr interface {
+	// String prints the expression in the syntax understood by CSFloat.
+	String() string
+	// Matches evaluates the expression against the given item.
+	Matches(item *csfloat.Item) bool
+
+	// precedence is used for printing the minimal amount of parentheses.
+	precedence() int
+}
+
+const (
+	precedenceOr = iota
+	precedenceAnd
+	precedenceNot
+	precedenceAtom
+)
+
+// Field is an item property that can be compared.
+type Field string
+
+const (
+	FloatValue Field = "FloatValue"
+	PaintSeed  Field = "PaintSeed"
+	PaintIndex Field = "PaintIndex"
+	DefIndex   Field = "DefIndex"
+	Rarity     Field = "Rarity"
+	// StatTrak and Souvenir are booleans, compare them with true or false.
+	StatTrak Field = "StatTrak"
+	Souvenir Field = "Souvenir"
+)
+
+var fields = []Field{FloatValue, PaintSeed, PaintIndex, DefIndex, Rarity, StatTrak, Souvenir}
+
+func (field Field) isBool() bool {
+	return field == StatTrak || field == Souvenir
+}
+
+// Operator is a comparison operator.
+type Operator string
+
+const (
+	Equal          Operator = "=="
+	NotEqual       Operator = "!="
+	Less           Operator = "<"
+	LessOrEqual    Operator = "<="
+	Greater        Operator = ">"
+	GreaterOrEqual Operator = ">="
+)
+
+// Comparison compares a field against a constant. For boolean fields, true is
+// represented by 1 and false by 0.
+type Comparison struct {
+	Field    Field
+	Operator Operator
+	Value    float64
+}
+
+func (comparison *Comparison) String() string {
+	value := strconv.FormatFloat(comparison.Value, 'f', -1, 64)
+	if comparison.Field.isBool() {
+		value = strconv.FormatBool(comparison.Value != 0)
+	}
+	return string(comparison.Field) + " " + string(comparison.Operator) + " " + value
+}
+
+func (*Comparison) precedence() int { return precedenceAtom }
+
+// HasSticker matches items with the given sticker. If Slot is set, the
+// sticker must be in that slot.
+type HasSticker struct {
+	Index uint
+	Slot  *uint
+}
+
+func (hasSticker *HasSticker) String() string {
+	if hasSticker.Slot != nil {
+		return "HasSticker(" + strconv.FormatUint(uint64(hasSticker.Index), 10) + ", " +
+			strconv.FormatUint(uint64(*hasSticker.Slot), 10) + ")"
+	}
+	return "HasSticker(" + strconv.FormatUint(uint64(hasSticker.Index), 10) + ")"
+}
+
+func (*HasSticker) precedence() int { return precedenceAtom }
+
+// HasKeychain matches items with the given charm attached.
+type HasKeychain struct {
+	Index uint
+}
+
+func (hasKeychain *HasKeychain) String() string {
+	return "HasKeychain(" + strconv.FormatUint(uint64(hasKeychain.Index), 10) + ")"
+}
+
+func (*HasKeychain) precedence() int { return precedenceAtom }
+
+// AndExpr matches if all of its operands match.
+type AndExpr struct {
+	Operands []Expr
+}
+
+func (and *AndExpr) String() string {
+	return join(and.Operands, " and ", precedenceAnd)
+}
+
+func (*AndExpr) precedence() int { return precedenceAnd }
+
+// OrExpr matches if any of its operands match.
+type OrExpr struct {
+	Operands []Expr
+}
+
+func (or *OrExpr) String() string {
+	return join(or.Operands, " or ", precedenceOr)
+}
+
+func (*OrExpr) precedence() int { return precedenceOr }
+
+// NotExpr negates its operand.
+type NotExpr struct {
+	Operand Expr
+}
+
+func (not *NotExpr) String() string {
+	// Comparisons are parenthesized too, as some parsers bind not tighter
+	// than comparisons.
+	switch not.Operand.(type) {
+	case *HasSticker, *HasKeychain, *NotExpr:
+		return "not " + not.Operand.String()
+	}
+	return "not (" + not.Operand.String() + ")"
+}
+
+func (*NotExpr) precedence() int { return precedenceNot }
+
+func join(operands []Expr, separator string, precedence int) string {
+	parts := make([]string, 0, len(operands))
+	for _, operand := range operands {
+		parts = append(parts, parenthesize(operand, precedence))
+	}
+	return strings.Join(parts, separator)
+}
+
+// parenthesize wraps the expression in parentheses, if it binds weaker than
+// its parent.
+func parenthesize(expr Expr, parent int) string {
+	if expr.precedence() < parent {
+		return "(" + expr.String() + ")"
+	}
+	return expr.String()
+}
@@ -0,0 +1,102 @@
+package expression
+
+func (field Field) compare(operator Operator, value float64) *Comparison {
+	return &Comparison{Field: field, Operator: operator, Value: value}
+}
+
+func (field Field) Eq(value float64) *Comparison  { return field.compare(Equal, value) }
+func (field Field) Ne(value float64) *Comparison  { return field.compare(NotEqual, value) }
+func (field Field) Lt(value float64) *Comparison  { return field.compare(Less, value) }
+func (field Field) Lte(value float64) *Comparison { return field.compare(LessOrEqual, value) }
+func (field Field) Gt(value float64) *Comparison  { return field.compare(Greater, value) }
+func (field Field) Gte(value float64) *Comparison { return field.compare(GreaterOrEqual, value) }
+
+// Is compares boolean fields, such as StatTrak.
+func (field Field) Is(value bool) *Comparison {
+	if value {
+		return field.compare(Equal, 1)
+	}
+	return field.compare(Equal, 0)
+}
+
+// Between matches values from min to max, both inclusive.
+func (field Field) Between(min, max float64) *AndExpr {
+	return And(field.Gte(min), field.Lte(max))
+}
+
+// Sticker matches items with the given sticker in any slot.
+func Sticker(index uint) *HasSticker {
+	return &HasSticker{Index: index}
+}
+
+// StickerInSlot matches items with the given sticker in the given slot.
+func StickerInSlot(index, slot uint) *HasSticker {
+	return &HasSticker{Index: index, Slot: &slot}
+}
+
+// Keychain matches items with the given charm.
+func Keychain(index uint) *HasKeychain {
+	return &HasKeychain{Index: index}
+}
+
+// And combines the operands, flattening nested AndExprs.
+func And(operands ...Expr) *AndExpr {
+	and := &AndExpr{}
+	for _, operand := range operands {
+		if nested, ok := operand.(*AndExpr); ok {
+			and.Operands = append(and.Operands, nested.Operands...)
+		} else {
+			and.Operands = append(and.Operands, operand)
+		}
+	}
+	return and
+}
+
+// Or combines the operands, flattening nested OrExprs.
+func Or(operands ...Expr) *OrExpr {
+	or := &OrExpr{}
+	for _, operand := range operands {
+		if nested, ok := operand.(*OrExpr); ok {
+			or.Operands = append(or.Operands, nested.Operands...)
+		} else {
+			or.Operands = append(or.Operands, operand)
+		}
+	}
+	return or
+}
+
+func Not(operand Expr) *NotExpr {
+	return &NotExpr{Operand: operand}
+}
+
+// Builder builds expressions fluently, for example:
+//
+//	Where(DefIndex.Eq(7)).And(FloatValue.Lt(0.01)).AndNot(StatTrak.Is(true)).Build()
+type Builder struct {
+	expr Expr
+}
+
+// Where starts a Builder with the given expression.
+func Where(expr Expr) *Builder {
+	return &Builder{expr: expr}
+}
+
+func (builder *Builder) And(expr Expr) *Builder {
+	builder.expr = And(builder.expr, expr)
+	return builder
+}
+
+func (builder *Builder) AndNot(expr Expr) *Builder {
+	return builder.And(Not(expr))
+}
+
+// Or combines everything built so far with the given expression, so
+// Where(a).And(b).Or(c) is (a and b) or c.
+func (builder *Builder) Or(expr Expr) *Builder {
+	builder.expr = Or(builder.expr, expr)
+	return builder
+}
+
+func (builder *Builder) Build() Expr {
+	return builder.expr
+}
@@ -0,0 +1,110 @@
+package expression
+
+import (
+	"fmt"
+
+	csfloat "github.com/Bios-Marcel/csfloat_go"
+)
+
+// value returns the item's value for the field. Boolean fields are 1 if true
+// and 0 if false.
+func (field Field) value(item *csfloat.Item) float64 {
+	switch field {
+	case FloatValue:
+		return item.Float
+	case PaintSeed:
+		return float64(item.PaintSeed)
+	case PaintIndex:
+		return float64(item.PaintIndex)
+	case DefIndex:
+		return float64(item.DefIndex)
+	case Rarity:
+		return float64(item.Rarity)
+	case StatTrak, Souvenir:
+		name, _ := csfloat.ParseMarketHashName(item.MarketHashName)
+		if (field == StatTrak && name.StatTrak) || (field == Souvenir && name.Souvenir) {
+			return 1
+		}
+	}
+	return 0
+}
+
+func (comparison *Comparison) Matches(item *csfloat.Item) bool {
+	value := comparison.Field.value(item)
+	switch comparison.Operator {
+	case Equal:
+		return value == comparison.Value
+	case NotEqual:
+		return value != comparison.Value
+	case Less:
+		return value < comparison.Value
+	case LessOrEqual:
+		return value <= comparison.Value
+	case Greater:
+		return value > comparison.Value
+	case GreaterOrEqual:
+		return value >= comparison.Value
+	}
+	return false
+}
+
+func (hasSticker *HasSticker) Matches(item *csfloat.Item) bool {
+	for _, sticker := range item.Stickers {
+		if sticker.Index == hasSticker.Index &&
+			(hasSticker.Slot == nil || sticker.Slot == *hasSticker.Slot) {
+			return true
+		}
+	}
+	return false
+}
+
+func (hasKeychain *HasKeychain) Matches(item *csfloat.Item) bool {
+	for _, charm := range item.Charms {
+		if charm.Index == hasKeychain.Index {
+			return true
+		}
+	}
+	return item.CharmIndex == hasKeychain.Index && hasKeychain.Index != 0
+}
+
+func (and *AndExpr) Matches(item *csfloat.Item) bool {
+	for _, operand := range and.Operands {
+		if !operand.Matches(item) {
+			return false
+		}
+	}
+	return true
+}
+
+func (or *OrExpr) Matches(item *csfloat.Item) bool {
+	for _, operand := range or.Operands {
+		if operand.Matches(item) {
+			return true
+		}
+	}
+	return false
+}
+
+func (not *NotExpr) Matches(item *csfloat.Item) bool {
+	return !not.Operand.Matches(item)
+}
+
+// MatchingOrders returns all advanced buy orders whose expression matches the
+// item, predicting which orders the item would fill. Simple buy orders are
+// skipped. It fails if any expression can't be parsed.
+func MatchingOrders(orders []csfloat.ItemBuyOrder, item *csfloat.Item) ([]csfloat.ItemBuyOrder, error) {
+	var matching []csfloat.ItemBuyOrder
+	for _, order := range orders {
+		if order.Expression == "" {
+			continue
+		}
+		expr, err := Parse(order.Expression)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing expression of order %s: %w", order.ID, err)
+		}
+		if expr.Matches(item) {
+			matching = append(matching, order)
+		}
+	}
+	return matching, nil
+}
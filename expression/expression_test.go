@@ -0,0 +1,164 @@
+package expression_test
+
+import (
+	"testing"
+
+	csfloat "github.com/Bios-Marcel/csfloat_go"
+	"github.com/Bios-Marcel/csfloat_go/expression"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParsePrint(t *testing.T) {
+	type testCase struct {
+		input string
+		// printed is only set if it differs from input.
+		printed string
+	}
+
+	testCases := []testCase{
+		{input: "FloatValue < 0.01"},
+		{input: "DefIndex == 7 and PaintIndex == 282 and FloatValue <= 0.16"},
+		{input: "DefIndex == 7 or DefIndex == 9"},
+		{input: "(DefIndex == 7 or DefIndex == 9) and StatTrak == true"},
+		{input: "DefIndex == 7 and PaintSeed == 661 or Souvenir == false"},
+		{input: "not (StatTrak == true)"},
+		{input: "HasSticker(5944) and not HasSticker(5945, 2) and HasKeychain(12)"},
+		{input: "FloatValue > -1 && !(Rarity != 6) || PaintSeed >= 1", printed: "FloatValue > -1 and not (Rarity != 6) or PaintSeed >= 1"},
+		{input: "((DefIndex == 7))", printed: "DefIndex == 7"},
+		{input: "DefIndex == 7 AND (PaintIndex == 1 OR PaintIndex == 2)", printed: "DefIndex == 7 and (PaintIndex == 1 or PaintIndex == 2)"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.input, func(t *testing.T) {
+			expr, err := expression.Parse(testCase.input)
+			if !assert.NoError(t, err) {
+				return
+			}
+			printed := testCase.printed
+			if printed == "" {
+				printed = testCase.input
+			}
+			assert.Equal(t, printed, expr.String())
+
+			reparsed, err := expression.Parse(expr.String())
+			if assert.NoError(t, err) {
+				assert.Equal(t, expr, reparsed)
+			}
+		})
+	}
+}
+
+func Test_ParseInvalid(t *testing.T) {
+	for _, input := range []string{
+		"",
+		"FloatValue",
+		"FloatValue <",
+		"Unknown == 1",
+		"StatTrak < true",
+		"StatTrak == 1",
+		"DefIndex == true",
+		"(DefIndex == 7",
+		"DefIndex == 7)",
+		"HasSticker()",
+		"HasSticker(1, 2, 3)",
+		"Unknown(1)",
+		"DefIndex == 7 and",
+		"DefIndex = 7",
+		"DefIndex == 7 # comment",
+	} {
+		_, err := expression.Parse(input)
+		assert.ErrorIs(t, err, expression.ErrSyntax, input)
+	}
+}
+
+func Test_Matches(t *testing.T) {
+	item := &csfloat.Item{
+		MarketHashName: "StatTrak™ AK-47 | Redline (Field-Tested)",
+		DefIndex:       7,
+		PaintIndex:     282,
+		PaintSeed:      661,
+		Float:          0.1534,
+		Rarity:         csfloat.Classified,
+		Stickers:       []csfloat.Sticker{{Index: 5944, Slot: 2}},
+		Charms:         []csfloat.Charm{{Index: 12}},
+	}
+
+	for input, expected := range map[string]bool{
+		"DefIndex == 7 and PaintIndex == 282":      true,
+		"FloatValue < 0.15":                        false,
+		"FloatValue >= 0.15 and FloatValue < 0.16": true,
+		"StatTrak == true":                         true,
+		"Souvenir == true":                         false,
+		"Rarity == 5":                              true,
+		"HasSticker(5944)":                         true,
+		"HasSticker(5944, 2)":                      true,
+		"HasSticker(5944, 1)":                      false,
+		"HasKeychain(12)":                          true,
+		"HasKeychain(13) or PaintSeed == 661":      true,
+		"not (PaintSeed == 661)":                   false,
+	} {
+		expr, err := expression.Parse(input)
+		if assert.NoError(t, err, input) {
+			assert.Equal(t, expected, expr.Matches(item), input)
+		}
+	}
+}
+
+func Test_Builder(t *testing.T) {
+	expr := expression.Where(expression.DefIndex.Eq(7)).
+		And(expression.FloatValue.Between(0.15, 0.16)).
+		AndNot(expression.StatTrak.Is(true)).
+		Or(expression.StickerInSlot(5944, 0)).
+		Build()
+	assert.Equal(t,
+		"DefIndex == 7 and FloatValue >= 0.15 and FloatValue <= 0.16 and not (StatTrak == true) or HasSticker(5944, 0)",
+		expr.String())
+}
+
+func Test_Generate(t *testing.T) {
+	ass := assert.New(t)
+	item := &csfloat.Item{
+		MarketHashName: "AK-47 | Redline (Field-Tested)",
+		DefIndex:       7,
+		PaintIndex:     282,
+		PaintSeed:      661,
+		Float:          0.15341234,
+		Stickers:       []csfloat.Sticker{{Index: 5944, Slot: 2}},
+	}
+
+	expr := expression.Generate(item, expression.GenerateOptions{})
+	ass.Equal("DefIndex == 7 and PaintIndex == 282 and StatTrak == false and FloatValue >= 0.153412 and "+
+		"FloatValue <= 0.153413 and PaintSeed == 661 and HasSticker(5944, 2)", expr.String())
+	ass.True(expr.Matches(item))
+
+	other := *item
+	other.PaintSeed = 1
+	ass.False(expr.Matches(&other))
+
+	loose := expression.Generate(item, expression.GenerateOptions{
+		FloatMargin:    0.01,
+		FloatPrecision: 2,
+		IgnoreSeed:     true,
+		IgnoreStickers: true,
+	})
+	ass.Equal("DefIndex == 7 and PaintIndex == 282 and StatTrak == false and FloatValue >= 0.14 and FloatValue <= 0.17", loose.String())
+	ass.True(loose.Matches(&other))
+}
+
+func Test_MatchingOrders(t *testing.T) {
+	ass := assert.New(t)
+	item := &csfloat.Item{DefIndex: 7, PaintIndex: 282, Float: 0.2}
+	orders := []csfloat.ItemBuyOrder{
+		{ID: "simple", MarketHashName: "AK-47 | Redline (Field-Tested)"},
+		{ID: "match", Expression: "DefIndex == 7 and FloatValue < 0.3"},
+		{ID: "no match", Expression: "DefIndex == 9"},
+	}
+
+	matching, err := expression.MatchingOrders(orders, item)
+	if ass.NoError(err) && ass.Len(matching, 1) {
+		ass.Equal("match", matching[0].ID)
+	}
+
+	_, err = expression.MatchingOrders([]csfloat.ItemBuyOrder{{ID: "broken", Expression: "DefIndex =="}}, item)
+	ass.ErrorIs(err, expression.ErrSyntax)
+}
@@ -0,0 +1,59 @@
+package expression
+
+import (
+	"math"
+
+	csfloat "github.com/Bios-Marcel/csfloat_go"
+)
+
+// GenerateOptions configures Generate.
+type GenerateOptions struct {
+	// FloatMargin widens the float range around the item's float. By default,
+	// the range only covers the item's float, rounded to FloatPrecision
+	// decimals.
+	FloatMargin float64
+	// FloatPrecision is the amount of decimals of the float bounds. Defaults
+	// to 6.
+	FloatPrecision int
+	// IgnoreSeed omits the paint seed, so items with any pattern match.
+	IgnoreSeed bool
+	// IgnoreStickers omits the stickers.
+	IgnoreStickers bool
+}
+
+// Generate derives an expression that matches the given item as tightly as
+// possible: its weapon, skin, StatTrak and Souvenir state, float, paint seed
+// and stickers in their slots. The options allow loosening it.
+func Generate(item *csfloat.Item, options GenerateOptions) Expr {
+	precision := options.FloatPrecision
+	if precision <= 0 {
+		precision = 6
+	}
+	scale := math.Pow10(precision)
+
+	expr := And(DefIndex.Eq(float64(item.DefIndex)))
+	if item.PaintIndex != 0 {
+		expr = And(expr, PaintIndex.Eq(float64(item.PaintIndex)))
+	}
+
+	name, _ := csfloat.ParseMarketHashName(item.MarketHashName)
+	expr = And(expr, StatTrak.Is(name.StatTrak))
+	if name.Souvenir {
+		expr = And(expr, Souvenir.Is(true))
+	}
+
+	if item.Float > 0 {
+		low := math.Floor((item.Float-options.FloatMargin)*scale) / scale
+		high := math.Ceil((item.Float+options.FloatMargin)*scale) / scale
+		expr = And(expr, FloatValue.Between(max(low, 0), min(high, 1)))
+	}
+	if !options.IgnoreSeed && item.PaintIndex != 0 {
+		expr = And(expr, PaintSeed.Eq(float64(item.PaintSeed)))
+	}
+	if !options.IgnoreStickers {
+		for _, sticker := range item.Stickers {
+			expr = And(expr, StickerInSlot(sticker.Index, sticker.Slot))
+		}
+	}
+	return expr
+}
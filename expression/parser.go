@@ -0,0 +1,304 @@
+package expression
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ErrSyntax is matched by all errors returned by Parse.
+var ErrSyntax = errors.New("invalid expression")
+
+// SyntaxError describes where parsing an expression failed.
+type SyntaxError struct {
+	// Offset is the byte offset of the offending token.
+	Offset  int
+	Message string
+}
+
+func (err *SyntaxError) Error() string {
+	return fmt.Sprintf("invalid expression at offset %d: %s", err.Offset, err.Message)
+}
+
+func (err *SyntaxError) Is(target error) bool {
+	return target == ErrSyntax
+}
+
+type tokenKind uint8
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenNumber
+	tokenOperator
+	tokenLeftParen
+	tokenRightParen
+	tokenComma
+)
+
+type token struct {
+	kind   tokenKind
+	text   string
+	offset int
+}
+
+func tokenize(input string) ([]token, error) {
+	var tokens []token
+	for offset := 0; offset < len(input); {
+		char := rune(input[offset])
+		start := offset
+		switch {
+		case unicode.IsSpace(char):
+			offset++
+			continue
+		case char == '(':
+			tokens = append(tokens, token{tokenLeftParen, "(", start})
+			offset++
+		case char == ')':
+			tokens = append(tokens, token{tokenRightParen, ")", start})
+			offset++
+		case char == ',':
+			tokens = append(tokens, token{tokenComma, ",", start})
+			offset++
+		case unicode.IsLetter(char) || char == '_':
+			for offset < len(input) && (unicode.IsLetter(rune(input[offset])) ||
+				unicode.IsDigit(rune(input[offset])) || input[offset] == '_') {
+				offset++
+			}
+			tokens = append(tokens, token{tokenIdent, input[start:offset], start})
+		case unicode.IsDigit(char) || char == '.' || char == '-':
+			offset++
+			for offset < len(input) && (unicode.IsDigit(rune(input[offset])) ||
+				input[offset] == '.' || input[offset] == 'e' || input[offset] == 'E') {
+				offset++
+			}
+			tokens = append(tokens, token{tokenNumber, input[start:offset], start})
+		default:
+			operator := ""
+			for _, candidate := range []string{"==", "!=", "<=", ">=", "&&", "||", "<", ">", "!"} {
+				if strings.HasPrefix(input[offset:], candidate) {
+					operator = candidate
+					break
+				}
+			}
+			if operator == "" {
+				return nil, &SyntaxError{Offset: start, Message: fmt.Sprintf("unexpected character %q", char)}
+			}
+			tokens = append(tokens, token{tokenOperator, operator, start})
+			offset += len(operator)
+		}
+	}
+	return append(tokens, token{tokenEOF, "", len(input)}), nil
+}
+
+type parser struct {
+	tokens   []token
+	position int
+}
+
+// Parse parses an expression. Both "and", "or" and "not" and their symbolic
+// forms "&&", "||" and "!" are accepted. Keywords, fields and functions are
+// case sensitive, except for the logical operators.
+func Parse(input string) (Expr, error) {
+	tokens, err := tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := &parser{tokens: tokens}
+	expr, err := parser.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if next := parser.peek(); next.kind != tokenEOF {
+		return nil, parser.unexpected(next)
+	}
+	return expr, nil
+}
+
+func (parser *parser) peek() token {
+	return parser.tokens[parser.position]
+}
+
+func (parser *parser) next() token {
+	token := parser.tokens[parser.position]
+	if token.kind != tokenEOF {
+		parser.position++
+	}
+	return token
+}
+
+func (parser *parser) unexpected(token token) error {
+	if token.kind == tokenEOF {
+		return &SyntaxError{Offset: token.offset, Message: "unexpected end of expression"}
+	}
+	return &SyntaxError{Offset: token.offset, Message: fmt.Sprintf("unexpected %q", token.text)}
+}
+
+func (parser *parser) expect(kind tokenKind, text string) error {
+	if token := parser.next(); token.kind != kind {
+		return &SyntaxError{Offset: token.offset, Message: fmt.Sprintf("expected %q", text)}
+	}
+	return nil
+}
+
+// isLogical reports whether the token is the given logical operator, either
+// as keyword or as symbol.
+func isLogical(token token, keyword, symbol string) bool {
+	return (token.kind == tokenIdent && strings.EqualFold(token.text, keyword)) ||
+		(token.kind == tokenOperator && token.text == symbol)
+}
+
+func (parser *parser) parseOr() (Expr, error) {
+	operand, err := parser.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	operands := []Expr{operand}
+	for isLogical(parser.peek(), "or", "||") {
+		parser.next()
+		operand, err := parser.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, operand)
+	}
+	if len(operands) == 1 {
+		return operands[0], nil
+	}
+	return &OrExpr{Operands: operands}, nil
+}
+
+func (parser *parser) parseAnd() (Expr, error) {
+	operand, err := parser.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	operands := []Expr{operand}
+	for isLogical(parser.peek(), "and", "&&") {
+		parser.next()
+		operand, err := parser.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, operand)
+	}
+	if len(operands) == 1 {
+		return operands[0], nil
+	}
+	return &AndExpr{Operands: operands}, nil
+}
+
+func (parser *parser) parseNot() (Expr, error) {
+	if isLogical(parser.peek(), "not", "!") {
+		parser.next()
+		operand, err := parser.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &NotExpr{Operand: operand}, nil
+	}
+	return parser.parsePrimary()
+}
+
+func (parser *parser) parsePrimary() (Expr, error) {
+	token := parser.next()
+	switch token.kind {
+	case tokenLeftParen:
+		expr, err := parser.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := parser.expect(tokenRightParen, ")"); err != nil {
+			return nil, err
+		}
+		return expr, nil
+	case tokenIdent:
+		if parser.peek().kind == tokenLeftParen {
+			return parser.parseCall(token)
+		}
+		return parser.parseComparison(token)
+	}
+	return nil, parser.unexpected(token)
+}
+
+func (parser *parser) parseComparison(fieldToken token) (Expr, error) {
+	var field Field
+	for _, candidate := range fields {
+		if string(candidate) == fieldToken.text {
+			field = candidate
+			break
+		}
+	}
+	if field == "" {
+		return nil, &SyntaxError{Offset: fieldToken.offset, Message: fmt.Sprintf("unknown field %q", fieldToken.text)}
+	}
+
+	operatorToken := parser.next()
+	operator := Operator(operatorToken.text)
+	switch operator {
+	case Equal, NotEqual, Less, LessOrEqual, Greater, GreaterOrEqual:
+	default:
+		return nil, &SyntaxError{Offset: operatorToken.offset, Message: "expected comparison operator"}
+	}
+
+	valueToken := parser.next()
+	comparison := &Comparison{Field: field, Operator: operator}
+	if field.isBool() {
+		if operator != Equal && operator != NotEqual {
+			return nil, &SyntaxError{Offset: operatorToken.offset, Message: fmt.Sprintf("%s can only be compared for equality", field)}
+		}
+		value, err := strconv.ParseBool(valueToken.text)
+		if err != nil || valueToken.kind != tokenIdent {
+			return nil, &SyntaxError{Offset: valueToken.offset, Message: "expected true or false"}
+		}
+		if value {
+			comparison.Value = 1
+		}
+		return comparison, nil
+	}
+
+	if valueToken.kind != tokenNumber {
+		return nil, &SyntaxError{Offset: valueToken.offset, Message: "expected number"}
+	}
+	value, err := strconv.ParseFloat(valueToken.text, 64)
+	if err != nil {
+		return nil, &SyntaxError{Offset: valueToken.offset, Message: fmt.Sprintf("invalid number %q", valueToken.text)}
+	}
+	comparison.Value = value
+	return comparison, nil
+}
+
+func (parser *parser) parseCall(function token) (Expr, error) {
+	parser.next()
+	var args []uint
+	for parser.peek().kind != tokenRightParen {
+		if len(args) > 0 {
+			if err := parser.expect(tokenComma, ","); err != nil {
+				return nil, err
+			}
+		}
+		argToken := parser.next()
+		arg, err := strconv.ParseUint(argToken.text, 10, 64)
+		if err != nil || argToken.kind != tokenNumber {
+			return nil, &SyntaxError{Offset: argToken.offset, Message: "expected non-negative integer"}
+		}
+		args = append(args, uint(arg))
+	}
+	parser.next()
+
+	switch {
+	case function.text == "HasSticker" && len(args) == 1:
+		return &HasSticker{Index: args[0]}, nil
+	case function.text == "HasSticker" && len(args) == 2:
+		return &HasSticker{Index: args[0], Slot: &args[1]}, nil
+	case function.text == "HasKeychain" && len(args) == 1:
+		return &HasKeychain{Index: args[0]}, nil
+	}
+	return nil, &SyntaxError{
+		Offset:  function.offset,
+		Message: fmt.Sprintf("unknown function %s with %d arguments", function.text, len(args)),
+	}
+}
@@ -2,13 +2,19 @@ package csfloat
 
 import (
 	"bytes"
+	"context"
 	json "encoding/json/v2"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptrace"
 	"net/url"
+	"slices"
 	"strconv"
 	"strings"
+	"sync/atomic"
+	"time"
 )
 
 type GenericResponse struct {
@@ -18,6 +24,9 @@ type GenericResponse struct {
 	// the server. However, there might still be other errors, for example when
 	// decoding the server response.
 	Error *Error `json:"-"`
+	// Attempts contains every attempt made for this request, including the
+	// last one. Unless a RetryPolicy is set, there's only a single attempt.
+	Attempts []Attempt `json:"-"`
 }
 
 func (response *GenericResponse) setRatelimits(ratelimits *Ratelimits) {
@@ -26,6 +35,9 @@ func (response *GenericResponse) setRatelimits(ratelimits *Ratelimits) {
 func (response *GenericResponse) setError(err *Error) {
 	response.Error = err
 }
+func (response *GenericResponse) setAttempts(attempts []Attempt) {
+	response.Attempts = attempts
+}
 func (response *GenericResponse) responseBody() any {
 	// By default, we don't carry any data here.
 	return nil
@@ -34,11 +46,15 @@ func (response *GenericResponse) responseBody() any {
 type Response interface {
 	setError(*Error)
 	setRatelimits(*Ratelimits)
+	setAttempts([]Attempt)
 	// responseBody must return any pointer value that we'll JSON-decode into.
 	responseBody() any
 }
 
+// handleRequest sends the request, retrying it according to the RetryPolicy.
+// The endpoint is a path, such as "/me", relative to the base URL.
 func handleRequest[T Response](
+	ctx context.Context,
 	api *API,
 	bucketKey RatelimitBucketKey,
 	client *http.Client,
@@ -49,37 +65,122 @@ func handleRequest[T Response](
 	form url.Values,
 	result T,
 ) (T, error) {
-	var body io.Reader
-	var buffer bytes.Buffer
+	var body []byte
 	if payload != nil {
+		var buffer bytes.Buffer
 		if err := json.MarshalWrite(&buffer, payload); err != nil {
 			return result, fmt.Errorf("error encoding payload: %w", err)
 		}
-		body = &buffer
+		body = buffer.Bytes()
+	}
+
+	policy := api.retryPolicy.Load()
+	var attempts []Attempt
+	for number := uint(1); ; number++ {
+		startedAt := time.Now()
+		outcome, err := sendRequest(ctx, api, bucketKey, client, method, endpoint, apiKey, body, form, result)
+		attempts = append(attempts, Attempt{
+			Number:       number,
+			StartedAt:    startedAt,
+			Duration:     time.Since(startedAt),
+			StatusCode:   outcome.statusCode,
+			WroteRequest: outcome.wroteRequest,
+			Err:          err,
+		})
+		result.setAttempts(attempts)
+
+		if err == nil || !outcome.transient || policy == nil ||
+			!policy.allows(method, bucketKey) || number >= policy.MaxAttempts {
+			return result, err
+		}
+
+		backoff := policy.delay(number)
+		if untilRetry := time.Until(outcome.retryAt); untilRetry > backoff {
+			backoff = untilRetry
+		}
+		attempts[len(attempts)-1].Backoff = backoff
+
+		if sleep(ctx, backoff) != nil {
+			return result, err
+		}
 	}
+}
+
+// sendRequest does a single attempt of a request. The outcome is always set,
+// but only meaningful if an error is returned.
+func sendRequest[T Response](
+	ctx context.Context,
+	api *API,
+	bucketKey RatelimitBucketKey,
+	client *http.Client,
+	method string,
+	endpoint string,
+	apiKey string,
+	body []byte,
+	form url.Values,
+	result T,
+) (attemptOutcome, error) {
+	var outcome attemptOutcome
+	// A previous attempt might have failed, but this one could succeed.
+	result.setError(nil)
+
+	// The IP-based ratelimit is tracked per address family, so we need to
+	// know which connection was actually used.
+	// Whether the request was written tells failures that CSFloat can't have
+	// seen apart from ambiguous ones. The trace may fire from the transport's
+	// own goroutine.
+	var family AddressFamily
+	var wroteRequest atomic.Bool
+	tracedCtx := httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			family = addressFamilyOf(info.Conn.RemoteAddr())
+		},
+		WroteRequest: func(info httptrace.WroteRequestInfo) {
+			wroteRequest.Store(info.Err == nil)
+		},
+	})
 
-	request, err := http.NewRequest(
+	var bodyReader io.Reader
+	if body != nil {
+		bodyReader = bytes.NewReader(body)
+	}
+	request, err := http.NewRequestWithContext(
+		tracedCtx,
 		method,
-		endpoint,
-		body)
+		api.baseURL+endpoint,
+		bodyReader)
 	if err != nil {
-		return result, fmt.Errorf("error creating request: %w", err)
+		return outcome, fmt.Errorf("error creating request: %w", err)
 	}
 
 	request.URL.RawQuery = form.Encode()
 
+	for key, values := range api.headers {
+		request.Header[key] = slices.Clone(values)
+	}
+	if api.userAgent != "" {
+		request.Header.Set("User-Agent", api.userAgent)
+	}
+
 	if apiKey != "" {
 		request.Header.Set("Authorization", apiKey)
 	}
 	if body != nil {
 		request.Header.Set("Content-Type", "application/json")
-		request.Header.Set("Content-Length", strconv.Itoa(buffer.Len()))
+		request.Header.Set("Content-Length", strconv.Itoa(len(body)))
+	}
+
+	if err := api.awaitRatelimit(ctx, bucketKey); err != nil {
+		return outcome, err
 	}
 
 	response, err := client.Do(request)
+	outcome.wroteRequest = wroteRequest.Load()
 	if err != nil {
-		return result, fmt.Errorf("error sending request: %w", err)
+		outcome.transient = isTransientNetworkError(ctx, err)
+		return outcome, fmt.Errorf("error sending request: %w", err)
 	}
+	outcome.statusCode = response.StatusCode
 
 	if response.Body != nil {
 		// Make sure the connection is reusable by draining and closing the body.
@@ -90,7 +191,17 @@ func handleRequest[T Response](
 		}()
 	}
 
+	// Error pages by proxies in front of CSFloat are HTML and carry neither
+	// ratelimit headers nor the usual error format.
+	isHTML := strings.HasPrefix(response.Header.Get("Content-Type"), "text/html")
+	outcome.transient = isTransientStatus(response.StatusCode) || isHTML
+
 	ratelimits, err := ratelimitsFrom(response)
+	// A 429 with budget left in the bucket can only be caused by the IP-based
+	// ratelimit.
+	globallyLimited := response.StatusCode == http.StatusTooManyRequests &&
+		(err != nil || ratelimits.Remaining > 0)
+	api.recordIPRequest(family, time.Now(), globallyLimited)
 	if err != nil {
 		var bodyText string
 		if response.Body != nil {
@@ -101,35 +212,48 @@ func handleRequest[T Response](
 				bodyText = string(bytes)
 			}
 		}
-		return result, fmt.Errorf("error getting ratelimits (%d: %s): %w", response.StatusCode, bodyText, err)
+		if response.StatusCode != http.StatusOK {
+			// Allows matching by status, for example with ErrRatelimited.
+			err = errors.Join(err, &Error{HttpStatus: uint(response.StatusCode)})
+		}
+		return outcome, fmt.Errorf("error getting ratelimits (%d: %s): %w", response.StatusCode, bodyText, err)
 	}
 
 	// This SHOULD not happen!
 	if ratelimits.Limit <= 0 {
-		return result, fmt.Errorf("invalid ratelimit object")
+		return outcome, fmt.Errorf("invalid ratelimit object")
 	}
 
 	api.updateRatelimits(bucketKey, &ratelimits)
 	result.setRatelimits(&ratelimits)
 
+	if response.StatusCode == http.StatusTooManyRequests {
+		outcome.transient = true
+		if ratelimits.Remaining == 0 {
+			outcome.retryAt = ratelimits.Reset
+		}
+	}
+
 	if response.StatusCode != http.StatusOK {
 		csfloatError, err := errorFrom(response)
 		if err != nil {
-			return result, fmt.Errorf("invalid status code, couldn't read error message: %d",
-				response.StatusCode)
+			// Without a message, the error can still be matched by status.
+			return outcome, fmt.Errorf("invalid status code, couldn't read error message: %d: %w",
+				response.StatusCode, &Error{HttpStatus: uint(response.StatusCode)})
 		}
 		result.setError(&csfloatError)
+		api.unknownErrorCodes.record(&csfloatError)
 
-		return result, fmt.Errorf("invalid status code: %d; %v", response.StatusCode, csfloatError)
+		return outcome, fmt.Errorf("invalid status code: %d: %w", response.StatusCode, &csfloatError)
 	}
 
 	if target := result.responseBody(); target != nil {
 		if err := json.UnmarshalRead(response.Body, target); err != nil {
-			return result, fmt.Errorf("error decoding response: %w", err)
+			return outcome, fmt.Errorf("error decoding response: %w", err)
 		}
 	}
 
-	return result, nil
+	return outcome, nil
 }
 
 func concatInts[Number ~int | ~uint](n ...Number) string {
@@ -0,0 +1,149 @@
+package csfloat
+
+import (
+	"net"
+	"time"
+)
+
+// IPRatelimitWindow is the window of the IP-based ratelimit. CSFloat allows
+// N requests per window, where N is unknown.
+const IPRatelimitWindow = 5 * time.Minute
+
+// AddressFamily is the IP version of the connection a request was sent over.
+// CSFloat tracks the IP-based ratelimit separately for each family.
+type AddressFamily string
+
+const (
+	IPv4 AddressFamily = "ipv4"
+	IPv6 AddressFamily = "ipv6"
+)
+
+// IPRatelimits describes the IP-based ratelimit of a single address family.
+// Note that if you are using a proxy, the family is the one of the connection
+// to the proxy.
+type IPRatelimits struct {
+	Family AddressFamily
+	// Limit is the amount of requests allowed per IPRatelimitWindow. It is 0
+	// if the limit is unknown.
+	Limit uint
+	// Learned is true if Limit wasn't configured via SetIPRatelimit, but
+	// derived from running into the ratelimit.
+	Learned bool
+	// Used is the amount of requests sent within the current window.
+	Used uint
+	// Reset is the time at which the oldest request in the window drops out
+	// of it. It is zero if Used is 0.
+	Reset time.Time
+}
+
+// Remaining returns the amount of requests left in the current window. If
+// the limit is unknown, false is returned.
+func (ratelimits IPRatelimits) Remaining() (uint, bool) {
+	if ratelimits.Limit == 0 {
+		return 0, false
+	}
+	if ratelimits.Used >= ratelimits.Limit {
+		return 0, true
+	}
+	return ratelimits.Limit - ratelimits.Used, true
+}
+
+// ipWindow is a sliding window of request timestamps for one address family.
+type ipWindow struct {
+	limit   uint
+	learned bool
+	// requests is sorted in ascending order.
+	requests []time.Time
+}
+
+// expired returns the amount of requests that have dropped out of the window.
+func (window *ipWindow) expired(now time.Time) int {
+	cutoff := now.Add(-IPRatelimitWindow)
+	var expired int
+	for expired < len(window.requests) && !window.requests[expired].After(cutoff) {
+		expired++
+	}
+	return expired
+}
+
+// SetIPRatelimit configures the amount of requests allowed per
+// IPRatelimitWindow for the given family. Setting it to 0 makes the limit
+// unknown again, allowing it to be learned.
+func (api *API) SetIPRatelimit(family AddressFamily, limit uint) {
+	api.ratelimitsLock.Lock()
+	defer api.ratelimitsLock.Unlock()
+
+	window := api.ipWindow(family)
+	window.limit = limit
+	window.learned = false
+}
+
+// IPRatelimits returns the current state of the IP-based ratelimit for the
+// given family.
+func (api *API) IPRatelimits(family AddressFamily) IPRatelimits {
+	api.ratelimitsLock.RLock()
+	defer api.ratelimitsLock.RUnlock()
+
+	return api.ipRatelimits(family, time.Now())
+}
+
+// ipRatelimits requires the caller to hold ratelimitsLock.
+func (api *API) ipRatelimits(family AddressFamily, now time.Time) IPRatelimits {
+	ratelimits := IPRatelimits{Family: family}
+	window, ok := api.ipWindows[family]
+	if !ok {
+		return ratelimits
+	}
+
+	ratelimits.Limit = window.limit
+	ratelimits.Learned = window.learned
+	inWindow := window.requests[window.expired(now):]
+	ratelimits.Used = uint(len(inWindow))
+	if len(inWindow) > 0 {
+		ratelimits.Reset = inWindow[0].Add(IPRatelimitWindow)
+	}
+	return ratelimits
+}
+
+// ipWindow requires the caller to hold ratelimitsLock for writing.
+func (api *API) ipWindow(family AddressFamily) *ipWindow {
+	window, ok := api.ipWindows[family]
+	if !ok {
+		window = &ipWindow{}
+		api.ipWindows[family] = window
+	}
+	return window
+}
+
+// recordIPRequest adds a request that reached the server to the window of
+// the given family. If globallyLimited is true, the request was rejected due
+// to the IP-based ratelimit, meaning the requests before it in the window
+// were exactly the limit. Unless configured explicitly, the limit is learned
+// from that.
+func (api *API) recordIPRequest(family AddressFamily, at time.Time, globallyLimited bool) {
+	if family == "" {
+		return
+	}
+
+	api.ratelimitsLock.Lock()
+	defer api.ratelimitsLock.Unlock()
+
+	window := api.ipWindow(family)
+	window.requests = window.requests[window.expired(at):]
+	if globallyLimited && (window.limit == 0 || window.learned) && len(window.requests) > 0 {
+		window.limit = uint(len(window.requests))
+		window.learned = true
+	}
+	window.requests = append(window.requests, at)
+}
+
+func addressFamilyOf(addr net.Addr) AddressFamily {
+	tcpAddr, ok := addr.(*net.TCPAddr)
+	if !ok {
+		return ""
+	}
+	if tcpAddr.IP.To4() != nil {
+		return IPv4
+	}
+	return IPv6
+}
@@ -0,0 +1,30 @@
+package csfloat
+
+import "github.com/Bios-Marcel/csfloat_go/catalog"
+
+// CatalogName builds the item's name from its DefIndex and PaintIndex via the
+// embedded catalog, for example "AK-47 | Redline (Field-Tested)". Unlike
+// the MarketHashName, it includes the Doppler phase. StatTrak and Souvenir
+// are taken from the MarketHashName, if set. It returns false if the weapon
+// isn't in the catalog.
+func (item *Item) CatalogName() (MarketHashName, bool) {
+	weapon, ok := catalog.Default().Weapon(item.DefIndex)
+	if !ok {
+		return MarketHashName{}, false
+	}
+
+	name, _ := ParseMarketHashName(item.MarketHashName)
+	name.Star = weapon.Star
+	name.Weapon = weapon.Name
+	name.Skin = ""
+	name.Phase = ""
+	name.Wear = ""
+	if paint, ok := catalog.Default().Paint(item.PaintIndex); ok {
+		name.Skin = paint.Name
+		name.Phase = Phase(paint.Phase)
+	}
+	if name.Skin != "" && item.Float > 0 {
+		name.Wear = wearNameOf(item.Float)
+	}
+	return name, true
+}
@@ -0,0 +1,29 @@
+package csfloat_test
+
+import (
+	"testing"
+
+	csfloat "github.com/Bios-Marcel/csfloat_go"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ItemCatalogName(t *testing.T) {
+	ass := assert.New(t)
+
+	item := csfloat.Item{DefIndex: 507, PaintIndex: 419, Float: 0.01, MarketHashName: "★ StatTrak™ Karambit | Doppler (Factory New)"}
+	name, ok := item.CatalogName()
+	if ass.True(ok) {
+		ass.Equal(csfloat.Phase2, name.Phase)
+		ass.Equal("★ StatTrak™ Karambit | Doppler (Factory New)", name.String())
+	}
+
+	item = csfloat.Item{DefIndex: 7, PaintIndex: 282, Float: 0.2}
+	name, ok = item.CatalogName()
+	if ass.True(ok) {
+		ass.Equal("AK-47 | Redline (Field-Tested)", name.String())
+	}
+
+	item = csfloat.Item{DefIndex: 1234567}
+	_, ok = item.CatalogName()
+	ass.False(ok)
+}
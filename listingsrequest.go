@@ -0,0 +1,361 @@
+package csfloat
+
+import (
+	json "encoding/json/v2"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Values serializes the request into the query parameters used by the
+// website and the listings endpoint. Zero values are omitted.
+func (query ListingsRequest) Values() url.Values {
+	form := url.Values{}
+	if query.Limit > 0 {
+		form.Set("limit", strconv.FormatUint(uint64(query.Limit), 10))
+	} else {
+		form.Set("limit", "40")
+	}
+	if query.Cursor != "" {
+		form.Set("cursor", query.Cursor)
+	}
+	// Empty = BestDeals = Default
+	if query.SortBy != BestDeals {
+		form.Set("sort_by", string(query.SortBy))
+	}
+	if query.MinRefQuantity > 0 {
+		form.Set("min_ref_qty", strconv.FormatUint(uint64(query.MinRefQuantity), 10))
+	} else if query.ExcludeRare {
+		form.Set("min_ref_qty", strconv.FormatUint(20, 10))
+	}
+	setUint(form, "max_ref_qty", query.MaxRefQuantity)
+	setUint(form, "def_index", query.DefIndex)
+	setUint(form, "paint_index", query.PaintIndex)
+	if len(query.PaintSeed) > 0 {
+		form.Set("paint_seed", concatInts(query.PaintSeed...))
+	}
+	if len(query.Categories) > 0 {
+		// Since mid-may it supports multi value
+		form.Set("category", concatInts(query.Categories...))
+	}
+	if query.MinPrice > 0 {
+		form.Set("min_price", strconv.Itoa(query.MinPrice))
+	}
+	if query.MaxPrice > 0 {
+		form.Set("max_price", strconv.Itoa(query.MaxPrice))
+	}
+	if query.MinFloat > 0 {
+		form.Set("min_float", strconv.FormatFloat(float64(query.MinFloat), 'f', -1, 32))
+	}
+	if query.MaxFloat > 0 {
+		form.Set("max_float", strconv.FormatFloat(float64(query.MaxFloat), 'f', -1, 32))
+	}
+	setUint(form, "sticker_index", query.StickerIndex)
+	if len(query.Stickers) > 0 {
+		form.Set("stickers", encodeStickerFilters(query.Stickers))
+	}
+	setUint(form, "keychain_index", query.CharmIndex)
+	setUint(form, "keychain_highlight_reel", query.CharmHighlightReel)
+	if query.Type != "" {
+		form.Set("type", string(query.Type))
+	}
+	if query.MarketHashName != "" {
+		form.Set("market_hash_name", query.MarketHashName)
+	}
+	setUint(form, "rarity", uint(query.Rarity))
+	if query.Collection != "" {
+		form.Set("collection", query.Collection)
+	}
+	setFloat(form, "min_fade_percentage", query.MinFade)
+	setFloat(form, "max_fade_percentage", query.MaxFade)
+	setFloat(form, "min_playside_blue", query.MinPlaysideBlue)
+	setFloat(form, "max_playside_blue", query.MaxPlaysideBlue)
+	setFloat(form, "min_backside_blue", query.MinBacksideBlue)
+	setFloat(form, "max_backside_blue", query.MaxBacksideBlue)
+	if query.SellerID != "" {
+		form.Set("user_id", query.SellerID)
+	}
+	return form
+}
+
+func setUint(form url.Values, key string, value uint) {
+	if value > 0 {
+		form.Set(key, strconv.FormatUint(uint64(value), 10))
+	}
+}
+
+func setFloat(form url.Values, key string, value float64) {
+	if value > 0 {
+		form.Set(key, strconv.FormatFloat(value, 'f', -1, 64))
+	}
+}
+
+// stickerFilter is the website's encoding of StickerFilter.
+type stickerFilter struct {
+	Index string `json:"i"`
+	Slot  *uint  `json:"s,omitempty"`
+}
+
+// encodeStickerFilters encodes the filters like the website does, for example
+// [{"i":"5944","s":2},{"i":"5945"}].
+func encodeStickerFilters(filters []StickerFilter) string {
+	encoded := make([]stickerFilter, 0, len(filters))
+	for _, filter := range filters {
+		encoded = append(encoded, stickerFilter{
+			Index: strconv.FormatUint(uint64(filter.Index), 10),
+			Slot:  filter.Slot,
+		})
+	}
+	// This can't fail, as it only contains strings and numbers.
+	data, _ := json.Marshal(encoded)
+	return string(data)
+}
+
+// WebsiteURL is the base URL of all pages on the website.
+const WebsiteURL = "https://csfloat.com"
+
+// MaxPaintSeeds is the maximum amount of paint seeds per ListingsRequest.
+const MaxPaintSeeds = 100
+
+// ErrInvalidListingsRequest is returned by ListingsRequest.Validate.
+var ErrInvalidListingsRequest = errors.New("invalid listings request")
+
+// ErrInvalidURL is returned by ParseSearchURL, ParseItemURL and
+// ParseStallURL if the URL doesn't point to the respective page.
+var ErrInvalidURL = errors.New("invalid csfloat url")
+
+// Validate reports all problems with the request that would make CSFloat
+// reject it or return nothing. All errors match ErrInvalidListingsRequest.
+func (query ListingsRequest) Validate() error {
+	var errs []error
+	invalid := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%w: "+format, append([]any{ErrInvalidListingsRequest}, args...)...))
+	}
+
+	if query.MinPrice < 0 || query.MaxPrice < 0 {
+		invalid("negative price")
+	} else if query.MaxPrice > 0 && query.MinPrice > query.MaxPrice {
+		invalid("min price %d exceeds max price %d", query.MinPrice, query.MaxPrice)
+	}
+	if query.MinFloat < 0 || query.MaxFloat < 0 || query.MinFloat > 1 || query.MaxFloat > 1 {
+		invalid("float out of range [0, 1]")
+	} else if query.MaxFloat > 0 && query.MinFloat > query.MaxFloat {
+		invalid("min float %v exceeds max float %v", query.MinFloat, query.MaxFloat)
+	}
+	if len(query.PaintSeed) > MaxPaintSeeds {
+		invalid("%d paint seeds exceed the maximum of %d", len(query.PaintSeed), MaxPaintSeeds)
+	}
+	if query.Limit > 50 {
+		invalid("limit %d exceeds the maximum of 50", query.Limit)
+	}
+	if query.MaxRefQuantity > 0 && query.MinRefQuantity > query.MaxRefQuantity {
+		invalid("min reference quantity %d exceeds max reference quantity %d",
+			query.MinRefQuantity, query.MaxRefQuantity)
+	}
+	for _, percentage := range []struct {
+		name     string
+		min, max float64
+	}{
+		{"fade", query.MinFade, query.MaxFade},
+		{"playside blue", query.MinPlaysideBlue, query.MaxPlaysideBlue},
+		{"backside blue", query.MinBacksideBlue, query.MaxBacksideBlue},
+	} {
+		if percentage.min < 0 || percentage.max < 0 || percentage.min > 100 || percentage.max > 100 {
+			invalid("%s percentage out of range [0, 100]", percentage.name)
+		} else if percentage.max > 0 && percentage.min > percentage.max {
+			invalid("min %s %v exceeds max %s %v", percentage.name, percentage.min, percentage.name, percentage.max)
+		}
+	}
+	for _, sticker := range query.Stickers {
+		if sticker.Slot != nil && *sticker.Slot > 4 {
+			invalid("sticker slot %d out of range [0, 4]", *sticker.Slot)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// URL returns the website's search page for the request. Limit and Cursor
+// aren't part of it, see ParseSearchURL.
+func (query ListingsRequest) URL() string {
+	values := query.Values()
+	values.Del("limit")
+	values.Del("cursor")
+	return WebsiteURL + "/search?" + values.Encode()
+}
+
+// ItemURL returns the website's page for the given listing.
+func ItemURL(listingId string) string {
+	return WebsiteURL + "/item/" + listingId
+}
+
+// StallURL returns the website's page for the stall of the given user.
+func StallURL(steamId string) string {
+	return WebsiteURL + "/stall/" + steamId
+}
+
+// parseWebsiteURL parses a URL pointing to the website and returns its path
+// segments.
+func parseWebsiteURL(rawURL string) (*url.URL, []string, error) {
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrInvalidURL, err)
+	}
+	if host := strings.TrimPrefix(parsed.Hostname(), "www."); host != "csfloat.com" {
+		return nil, nil, fmt.Errorf("%w: unexpected host %q", ErrInvalidURL, parsed.Hostname())
+	}
+	return parsed, strings.Split(strings.Trim(parsed.Path, "/"), "/"), nil
+}
+
+// ParseItemURL returns the listing ID of an item URL, such as
+// https://csfloat.com/item/123.
+func ParseItemURL(rawURL string) (string, error) {
+	_, segments, err := parseWebsiteURL(rawURL)
+	if err != nil {
+		return "", err
+	}
+	if len(segments) != 2 || segments[0] != "item" || segments[1] == "" {
+		return "", fmt.Errorf("%w: not an item url: %q", ErrInvalidURL, rawURL)
+	}
+	return segments[1], nil
+}
+
+// ParseStallURL returns the steam ID of a stall URL, such as
+// https://csfloat.com/stall/76561198000000000.
+func ParseStallURL(rawURL string) (string, error) {
+	_, segments, err := parseWebsiteURL(rawURL)
+	if err != nil {
+		return "", err
+	}
+	if len(segments) != 2 || segments[0] != "stall" || segments[1] == "" {
+		return "", fmt.Errorf("%w: not a stall url: %q", ErrInvalidURL, rawURL)
+	}
+	return segments[1], nil
+}
+
+// ParseSearchURL converts a search URL from the website into a request. Stall
+// URLs are supported too, resulting in a request with SellerID set. Unknown
+// query parameters are ignored. ExcludeRare is never set, instead the
+// resulting MinRefQuantity is 20.
+func ParseSearchURL(rawURL string) (ListingsRequest, error) {
+	parsed, segments, err := parseWebsiteURL(rawURL)
+	if err != nil {
+		return ListingsRequest{}, err
+	}
+
+	var query ListingsRequest
+	switch {
+	case len(segments) == 1 && segments[0] == "search":
+	case len(segments) == 2 && segments[0] == "stall" && segments[1] != "":
+		query.SellerID = segments[1]
+	default:
+		return ListingsRequest{}, fmt.Errorf("%w: not a search url: %q", ErrInvalidURL, rawURL)
+	}
+
+	if err := query.parseValues(parsed.Query()); err != nil {
+		return ListingsRequest{}, err
+	}
+	return query, nil
+}
+
+// parseValues is the inverse of Values.
+func (query *ListingsRequest) parseValues(values url.Values) error {
+	var errs []error
+	parseUint := func(key string, target *uint) {
+		if value := values.Get(key); value != "" {
+			parsed, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%w: invalid %s: %w", ErrInvalidURL, key, err))
+			}
+			*target = uint(parsed)
+		}
+	}
+	parseInt := func(key string, target *int) {
+		if value := values.Get(key); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%w: invalid %s: %w", ErrInvalidURL, key, err))
+			}
+			*target = parsed
+		}
+	}
+	parseFloat := func(key string, bitSize int) float64 {
+		value := values.Get(key)
+		if value == "" {
+			return 0
+		}
+		parsed, err := strconv.ParseFloat(value, bitSize)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%w: invalid %s: %w", ErrInvalidURL, key, err))
+		}
+		return parsed
+	}
+	parseUints := func(key string) []uint {
+		value := values.Get(key)
+		if value == "" {
+			return nil
+		}
+		var parsed []uint
+		for part := range strings.SplitSeq(value, ",") {
+			number, err := strconv.ParseUint(strings.TrimSpace(part), 10, 64)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%w: invalid %s: %w", ErrInvalidURL, key, err))
+				continue
+			}
+			parsed = append(parsed, uint(number))
+		}
+		return parsed
+	}
+
+	parseUint("limit", &query.Limit)
+	query.Cursor = values.Get("cursor")
+	query.SortBy = SortListingsBy(values.Get("sort_by"))
+	parseUint("min_ref_qty", &query.MinRefQuantity)
+	parseUint("max_ref_qty", &query.MaxRefQuantity)
+	parseUint("def_index", &query.DefIndex)
+	parseUint("paint_index", &query.PaintIndex)
+	query.PaintSeed = parseUints("paint_seed")
+	for _, category := range parseUints("category") {
+		query.Categories = append(query.Categories, Category(category))
+	}
+	parseInt("min_price", &query.MinPrice)
+	parseInt("max_price", &query.MaxPrice)
+	query.MinFloat = float32(parseFloat("min_float", 32))
+	query.MaxFloat = float32(parseFloat("max_float", 32))
+	parseUint("sticker_index", &query.StickerIndex)
+	if value := values.Get("stickers"); value != "" {
+		var filters []stickerFilter
+		if err := json.Unmarshal([]byte(value), &filters); err != nil {
+			errs = append(errs, fmt.Errorf("%w: invalid stickers: %w", ErrInvalidURL, err))
+		}
+		for _, filter := range filters {
+			index, err := strconv.ParseUint(filter.Index, 10, 64)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%w: invalid sticker index: %w", ErrInvalidURL, err))
+				continue
+			}
+			query.Stickers = append(query.Stickers, StickerFilter{Index: uint(index), Slot: filter.Slot})
+		}
+	}
+	parseUint("keychain_index", &query.CharmIndex)
+	parseUint("keychain_highlight_reel", &query.CharmHighlightReel)
+	query.Type = ListingType(values.Get("type"))
+	query.MarketHashName = values.Get("market_hash_name")
+	var rarity uint
+	parseUint("rarity", &rarity)
+	query.Rarity = Rarity(rarity)
+	query.Collection = values.Get("collection")
+	query.MinFade = parseFloat("min_fade_percentage", 64)
+	query.MaxFade = parseFloat("max_fade_percentage", 64)
+	query.MinPlaysideBlue = parseFloat("min_playside_blue", 64)
+	query.MaxPlaysideBlue = parseFloat("max_playside_blue", 64)
+	query.MinBacksideBlue = parseFloat("min_backside_blue", 64)
+	query.MaxBacksideBlue = parseFloat("max_backside_blue", 64)
+	if sellerId := values.Get("user_id"); sellerId != "" {
+		query.SellerID = sellerId
+	}
+
+	return errors.Join(errs...)
+}
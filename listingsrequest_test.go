@@ -0,0 +1,248 @@
+package csfloat_test
+
+import (
+	"net/http"
+	"testing"
+
+	csfloat "github.com/Bios-Marcel/csfloat_go"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ListingsRequestValues(t *testing.T) {
+	type testCase struct {
+		name     string
+		request  csfloat.ListingsRequest
+		expected string
+	}
+
+	slot := uint(2)
+	testCases := []testCase{
+		{
+			name:     "default",
+			request:  csfloat.ListingsRequest{},
+			expected: "limit=40",
+		},
+		{
+			name:     "market hash name",
+			request:  csfloat.ListingsRequest{MarketHashName: "★ StatTrak™ Karambit | Doppler (Factory New)"},
+			expected: "limit=40&market_hash_name=%E2%98%85+StatTrak%E2%84%A2+Karambit+%7C+Doppler+%28Factory+New%29",
+		},
+		{
+			name:     "rarity",
+			request:  csfloat.ListingsRequest{Rarity: csfloat.Covert},
+			expected: "limit=40&rarity=6",
+		},
+		{
+			name:     "collection",
+			request:  csfloat.ListingsRequest{Collection: "set_community_1"},
+			expected: "collection=set_community_1&limit=40",
+		},
+		{
+			name: "stickers",
+			request: csfloat.ListingsRequest{Stickers: []csfloat.StickerFilter{
+				{Index: 5944, Slot: &slot},
+				{Index: 5945},
+			}},
+			expected: "limit=40&stickers=%5B%7B%22i%22%3A%225944%22%2C%22s%22%3A2%7D%2C%7B%22i%22%3A%225945%22%7D%5D",
+		},
+		{
+			name:     "fade",
+			request:  csfloat.ListingsRequest{MinFade: 95.5, MaxFade: 100},
+			expected: "limit=40&max_fade_percentage=100&min_fade_percentage=95.5",
+		},
+		{
+			name: "blue gem",
+			request: csfloat.ListingsRequest{
+				MinPlaysideBlue: 50,
+				MaxPlaysideBlue: 90.25,
+				MinBacksideBlue: 30,
+				MaxBacksideBlue: 80,
+			},
+			expected: "limit=40&max_backside_blue=80&max_playside_blue=90.25&min_backside_blue=30&min_playside_blue=50",
+		},
+		{
+			name:     "ref quantity range",
+			request:  csfloat.ListingsRequest{MinRefQuantity: 5, MaxRefQuantity: 50},
+			expected: "limit=40&max_ref_qty=50&min_ref_qty=5",
+		},
+		{
+			name:     "exclude rare",
+			request:  csfloat.ListingsRequest{ExcludeRare: true},
+			expected: "limit=40&min_ref_qty=20",
+		},
+		{
+			name:     "seller",
+			request:  csfloat.ListingsRequest{SellerID: "76561198000000001"},
+			expected: "limit=40&user_id=76561198000000001",
+		},
+		{
+			name:     "stattrak only",
+			request:  csfloat.ListingsRequest{Categories: []csfloat.Category{csfloat.StatTrak}, DefIndex: 7},
+			expected: "category=2&def_index=7&limit=40",
+		},
+		{
+			name:     "normal and stattrak",
+			request:  csfloat.ListingsRequest{Categories: []csfloat.Category{csfloat.Normal, csfloat.StatTrak}},
+			expected: "category=1%2C2&limit=40",
+		},
+		{
+			name: "existing filters",
+			request: csfloat.ListingsRequest{
+				MinPrice:  100,
+				MaxPrice:  2000,
+				MinFloat:  0.15,
+				MaxFloat:  0.18,
+				SortBy:    csfloat.LowestPrice,
+				PaintSeed: []uint{661, 670},
+				Type:      csfloat.BuyNow,
+				Limit:     50,
+				Cursor:    "abc",
+			},
+			expected: "cursor=abc&limit=50&max_float=0.18&max_price=2000&min_float=0.15&min_price=100&paint_seed=661%2C670&sort_by=lowest_price&type=buy_now",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, testCase.request.Values().Encode())
+		})
+	}
+}
+
+func Test_ListingsNewFilters(t *testing.T) {
+	ass := assert.New(t)
+	server, api := newFakeServer(t)
+	server.AddListing(csfloat.ActiveListing{
+		Price:  100,
+		Item:   csfloat.Item{MarketHashName: "AK-47 | Redline (Field-Tested)", Rarity: csfloat.Classified},
+		Seller: csfloat.Seller{SteamID: "1"},
+	})
+	server.AddListing(csfloat.ActiveListing{
+		Price:  200,
+		Item:   csfloat.Item{MarketHashName: "AWP | Asiimov (Field-Tested)", Rarity: csfloat.Covert},
+		Seller: csfloat.Seller{SteamID: "2"},
+	})
+
+	listings, err := api.Listings(csfloat.ListingsRequest{Rarity: csfloat.Covert})
+	if ass.NoError(err) && ass.Len(listings.Data, 1) {
+		ass.Equal(200, listings.Data[0].Price)
+	}
+	listings, err = api.Listings(csfloat.ListingsRequest{SellerID: "1", MarketHashName: "AK-47 | Redline (Field-Tested)"})
+	if ass.NoError(err) && ass.Len(listings.Data, 1) {
+		ass.Equal(100, listings.Data[0].Price)
+	}
+}
+
+func Test_ListingsRequestURLRoundTrip(t *testing.T) {
+	ass := assert.New(t)
+	slot := uint(0)
+	request := csfloat.ListingsRequest{
+		MinPrice:        100,
+		MaxPrice:        2000,
+		MinFloat:        0.15,
+		MaxFloat:        0.18,
+		MinRefQuantity:  5,
+		MaxRefQuantity:  50,
+		Categories:      []csfloat.Category{csfloat.StatTrak},
+		SortBy:          csfloat.LowestPrice,
+		DefIndex:        7,
+		PaintIndex:      282,
+		PaintSeed:       []uint{661, 670},
+		Type:            csfloat.BuyNow,
+		MarketHashName:  "StatTrak™ AK-47 | Redline (Field-Tested)",
+		Rarity:          csfloat.Classified,
+		Collection:      "set_community_1",
+		Stickers:        []csfloat.StickerFilter{{Index: 5944, Slot: &slot}, {Index: 5945}},
+		MinFade:         90,
+		MaxFade:         100,
+		MinPlaysideBlue: 50,
+		SellerID:        "76561198000000001",
+	}
+
+	url := request.URL()
+	ass.Contains(url, "https://csfloat.com/search?")
+	ass.NotContains(url, "limit=")
+
+	parsed, err := csfloat.ParseSearchURL(url)
+	if ass.NoError(err) {
+		ass.Equal(request, parsed)
+	}
+}
+
+func Test_ParseSearchURL(t *testing.T) {
+	ass := assert.New(t)
+
+	request, err := csfloat.ParseSearchURL("https://csfloat.com/search?def_index=7&sort_by=most_recent&utm_source=discord")
+	if ass.NoError(err) {
+		ass.Equal(csfloat.ListingsRequest{DefIndex: 7, SortBy: csfloat.Newest}, request)
+	}
+
+	request, err = csfloat.ParseSearchURL("https://www.csfloat.com/stall/76561198000000001?sort_by=lowest_price")
+	if ass.NoError(err) {
+		ass.Equal(csfloat.ListingsRequest{SellerID: "76561198000000001", SortBy: csfloat.LowestPrice}, request)
+	}
+
+	for _, invalid := range []string{
+		"https://example.com/search?def_index=7",
+		"https://csfloat.com/item/123",
+		"https://csfloat.com/search?def_index=abc",
+		"https://csfloat.com/search?stickers=%5B",
+	} {
+		_, err = csfloat.ParseSearchURL(invalid)
+		ass.ErrorIs(err, csfloat.ErrInvalidURL, invalid)
+	}
+}
+
+func Test_ItemAndStallURLs(t *testing.T) {
+	ass := assert.New(t)
+	listing := csfloat.ActiveListing{ID: "123"}
+	ass.Equal("https://csfloat.com/item/123", listing.URL())
+
+	id, err := csfloat.ParseItemURL(listing.URL())
+	if ass.NoError(err) {
+		ass.Equal("123", id)
+	}
+	_, err = csfloat.ParseItemURL("https://csfloat.com/stall/1")
+	ass.ErrorIs(err, csfloat.ErrInvalidURL)
+
+	steamId, err := csfloat.ParseStallURL(csfloat.StallURL("76561198000000001"))
+	if ass.NoError(err) {
+		ass.Equal("76561198000000001", steamId)
+	}
+	_, err = csfloat.ParseStallURL("https://csfloat.com/item/1")
+	ass.ErrorIs(err, csfloat.ErrInvalidURL)
+}
+
+func Test_ListingsRequestValidate(t *testing.T) {
+	ass := assert.New(t)
+	ass.NoError(csfloat.ListingsRequest{}.Validate())
+	ass.NoError(csfloat.ListingsRequest{MinFloat: 0.1, MaxFloat: 0.2, MinPrice: 1, MaxPrice: 2}.Validate())
+
+	slot := uint(5)
+	for name, request := range map[string]csfloat.ListingsRequest{
+		"min float exceeds max":  {MinFloat: 0.2, MaxFloat: 0.1},
+		"float out of range":     {MaxFloat: 1.5},
+		"negative price":         {MinPrice: -1},
+		"min price exceeds max":  {MinPrice: 200, MaxPrice: 100},
+		"too many paint seeds":   {PaintSeed: make([]uint, csfloat.MaxPaintSeeds+1)},
+		"limit too large":        {Limit: 51},
+		"min fade exceeds max":   {MinFade: 90, MaxFade: 80},
+		"ref quantity exceeds":   {MinRefQuantity: 10, MaxRefQuantity: 5},
+		"sticker slot too large": {Stickers: []csfloat.StickerFilter{{Index: 1, Slot: &slot}}},
+	} {
+		ass.ErrorIs(request.Validate(), csfloat.ErrInvalidListingsRequest, name)
+	}
+}
+
+func Test_ListingsValidatesBeforeRequest(t *testing.T) {
+	ass := assert.New(t)
+	var requests int
+	api := newTestAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		writeJSON(w, `{"data": []}`)
+	}))
+
+	_, err := api.Listings(csfloat.ListingsRequest{MinFloat: 0.5, MaxFloat: 0.1})
+	ass.ErrorIs(err, csfloat.ErrInvalidListingsRequest)
+	ass.Zero(requests)
+}
@@ -0,0 +1,159 @@
+package csfloat
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	starPrefix     = "★ "
+	statTrakPrefix = "StatTrak™ "
+	souvenirPrefix = "Souvenir "
+)
+
+// Phase is the phase of Doppler and Gamma Doppler knives.
+type Phase string
+
+const (
+	Phase1     Phase = "Phase 1"
+	Phase2     Phase = "Phase 2"
+	Phase3     Phase = "Phase 3"
+	Phase4     Phase = "Phase 4"
+	Ruby       Phase = "Ruby"
+	Sapphire   Phase = "Sapphire"
+	BlackPearl Phase = "Black Pearl"
+	Emerald    Phase = "Emerald"
+)
+
+var phases = []Phase{Phase1, Phase2, Phase3, Phase4, Ruby, Sapphire, BlackPearl, Emerald}
+
+var wearNames = []WearName{FactoryNew, MinimalWear, FieldTested, WellWorn, BattleScarred}
+
+// ErrInvalidMarketHashName is returned by ParseMarketHashName.
+var ErrInvalidMarketHashName = errors.New("invalid market hash name")
+
+// MarketHashName is the structured form of a Steam market hash name, such as
+// "★ StatTrak™ Karambit | Doppler (Factory New)".
+type MarketHashName struct {
+	// Star is set for knives and gloves.
+	Star     bool
+	StatTrak bool
+	Souvenir bool
+	// Weapon is the first part of the name, for example "AK-47", "Karambit",
+	// "Sport Gloves", "Sticker" or "Operation Riptide Case". For agents, it is
+	// the agent's name.
+	Weapon string
+	// Skin is empty for vanilla knives and items without skin, such as cases.
+	// For stickers, it is the sticker's name, for agents the faction.
+	Skin string
+	// Variant is the sticker, patch or graffiti variant, such as "Holo" or
+	// "Gold".
+	Variant string
+	// Event is the tournament of stickers, patches and graffiti, for
+	// example "Katowice 2019".
+	Event string
+	// Wear is empty for items without wear.
+	Wear WearName
+	// Phase is the Doppler or Gamma Doppler phase, such as Phase2 or Ruby.
+	// It isn't part of Steam's market hash names, but some sites append it
+	// as " - Phase 2".
+	Phase Phase
+}
+
+// ParseMarketHashName parses a market hash name. As items without wear can be
+// named anything, only names that are obviously malformed, such as names with
+// empty parts, are rejected.
+func ParseMarketHashName(name string) (MarketHashName, error) {
+	var parsed MarketHashName
+	rest := strings.TrimSpace(name)
+
+	if after, ok := strings.CutPrefix(rest, starPrefix); ok {
+		parsed.Star = true
+		rest = after
+	}
+	if after, ok := strings.CutPrefix(rest, statTrakPrefix); ok {
+		parsed.StatTrak = true
+		rest = after
+	} else if after, ok := strings.CutPrefix(rest, souvenirPrefix); ok && strings.Contains(after, "|") {
+		// Souvenir packages start with the event, not with "Souvenir".
+		parsed.Souvenir = true
+		rest = after
+	}
+
+	for _, phase := range phases {
+		if before, ok := strings.CutSuffix(rest, " - "+string(phase)); ok {
+			parsed.Phase = phase
+			rest = before
+			break
+		}
+	}
+	for _, wear := range wearNames {
+		if before, ok := strings.CutSuffix(rest, " ("+string(wear)+")"); ok {
+			parsed.Wear = wear
+			rest = before
+			break
+		}
+	}
+
+	parts := strings.Split(rest, "|")
+	for index, part := range parts {
+		parts[index] = strings.TrimSpace(part)
+		if parts[index] == "" {
+			return MarketHashName{}, fmt.Errorf("%w: %q", ErrInvalidMarketHashName, name)
+		}
+	}
+	switch len(parts) {
+	case 3:
+		parsed.Event = parts[2]
+		fallthrough
+	case 2:
+		parsed.Skin = parts[1]
+		fallthrough
+	case 1:
+		parsed.Weapon = parts[0]
+	default:
+		return MarketHashName{}, fmt.Errorf("%w: %q", ErrInvalidMarketHashName, name)
+	}
+
+	// Skins don't have variants, so this only applies to items without wear.
+	if parsed.Wear == "" && strings.HasSuffix(parsed.Skin, ")") {
+		if index := strings.LastIndex(parsed.Skin, " ("); index > 0 {
+			parsed.Variant = parsed.Skin[index+2 : len(parsed.Skin)-1]
+			parsed.Skin = parsed.Skin[:index]
+		}
+	}
+
+	return parsed, nil
+}
+
+// String returns the canonical market hash name, as required by History,
+// SimpleItemBuyOrders and CreateSimpleBuyOrder. The Phase is omitted, as it
+// isn't part of market hash names.
+func (name MarketHashName) String() string {
+	var builder strings.Builder
+	if name.Star {
+		builder.WriteString(starPrefix)
+	}
+	if name.StatTrak {
+		builder.WriteString(statTrakPrefix)
+	} else if name.Souvenir {
+		builder.WriteString(souvenirPrefix)
+	}
+	builder.WriteString(name.Weapon)
+	if name.Skin != "" {
+		builder.WriteString(" | ")
+		builder.WriteString(name.Skin)
+		if name.Variant != "" {
+			builder.WriteString(" (" + name.Variant + ")")
+		}
+	}
+	if name.Event != "" {
+		builder.WriteString(" | ")
+		builder.WriteString(name.Event)
+	}
+	if name.Wear != "" {
+		builder.WriteString(" (" + string(name.Wear) + ")")
+	}
+	return builder.String()
+}
@@ -0,0 +1,117 @@
+package csfloat_test
+
+import (
+	"testing"
+
+	csfloat "github.com/Bios-Marcel/csfloat_go"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParseMarketHashName(t *testing.T) {
+	type testCase struct {
+		name     string
+		expected csfloat.MarketHashName
+		// canonical is only set if it differs from name.
+		canonical string
+	}
+
+	testCases := []testCase{
+		{
+			name:     "AK-47 | Redline (Field-Tested)",
+			expected: csfloat.MarketHashName{Weapon: "AK-47", Skin: "Redline", Wear: csfloat.FieldTested},
+		},
+		{
+			name: "StatTrak™ M4A1-S | Hyper Beast (Minimal Wear)",
+			expected: csfloat.MarketHashName{
+				StatTrak: true, Weapon: "M4A1-S", Skin: "Hyper Beast", Wear: csfloat.MinimalWear,
+			},
+		},
+		{
+			name: "Souvenir AWP | Dragon Lore (Factory New)",
+			expected: csfloat.MarketHashName{
+				Souvenir: true, Weapon: "AWP", Skin: "Dragon Lore", Wear: csfloat.FactoryNew,
+			},
+		},
+		{
+			name: "★ StatTrak™ Karambit | Doppler (Factory New)",
+			expected: csfloat.MarketHashName{
+				Star: true, StatTrak: true, Weapon: "Karambit", Skin: "Doppler", Wear: csfloat.FactoryNew,
+			},
+		},
+		{
+			name: "★ Karambit | Gamma Doppler (Factory New) - Emerald",
+			expected: csfloat.MarketHashName{
+				Star: true, Weapon: "Karambit", Skin: "Gamma Doppler", Wear: csfloat.FactoryNew, Phase: csfloat.Emerald,
+			},
+			canonical: "★ Karambit | Gamma Doppler (Factory New)",
+		},
+		{
+			name:     "★ Butterfly Knife",
+			expected: csfloat.MarketHashName{Star: true, Weapon: "Butterfly Knife"},
+		},
+		{
+			name: "★ Sport Gloves | Vice (Battle-Scarred)",
+			expected: csfloat.MarketHashName{
+				Star: true, Weapon: "Sport Gloves", Skin: "Vice", Wear: csfloat.BattleScarred,
+			},
+		},
+		{
+			name: "Sticker | Team Liquid (Holo) | Katowice 2019",
+			expected: csfloat.MarketHashName{
+				Weapon: "Sticker", Skin: "Team Liquid", Variant: "Holo", Event: "Katowice 2019",
+			},
+		},
+		{
+			name:     "Sticker | Crown (Foil)",
+			expected: csfloat.MarketHashName{Weapon: "Sticker", Skin: "Crown", Variant: "Foil"},
+		},
+		{
+			name:     "Charm | Die-cast AK",
+			expected: csfloat.MarketHashName{Weapon: "Charm", Skin: "Die-cast AK"},
+		},
+		{
+			name:     "ESL One Cologne 2015 Cobblestone Souvenir Package",
+			expected: csfloat.MarketHashName{Weapon: "ESL One Cologne 2015 Cobblestone Souvenir Package"},
+		},
+		{
+			name:     "Sir Bloody Miami Darryl | The Professionals",
+			expected: csfloat.MarketHashName{Weapon: "Sir Bloody Miami Darryl", Skin: "The Professionals"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			parsed, err := csfloat.ParseMarketHashName(testCase.name)
+			if assert.NoError(t, err) {
+				assert.Equal(t, testCase.expected, parsed)
+
+				canonical := testCase.canonical
+				if canonical == "" {
+					canonical = testCase.name
+				}
+				assert.Equal(t, canonical, parsed.String())
+			}
+		})
+	}
+}
+
+func Test_ParseMarketHashNameInvalid(t *testing.T) {
+	for _, name := range []string{"", "AK-47 | ", "a | b | c | d"} {
+		_, err := csfloat.ParseMarketHashName(name)
+		assert.ErrorIs(t, err, csfloat.ErrInvalidMarketHashName, name)
+	}
+}
+
+func Test_ItemCategory(t *testing.T) {
+	ass := assert.New(t)
+	for name, category := range map[string]csfloat.Category{
+		"AK-47 | Redline (Field-Tested)":               csfloat.Normal,
+		"StatTrak™ AK-47 | Redline (Field-Tested)":     csfloat.StatTrak,
+		"Souvenir AWP | Dragon Lore (Factory New)":     csfloat.Souvenir,
+		"★ StatTrak™ Karambit | Doppler (Factory New)": csfloat.StatTrak,
+		"★ Karambit | Doppler (Factory New)":           csfloat.Normal,
+	} {
+		item := csfloat.Item{MarketHashName: name}
+		ass.Equal(category, item.Category(), name)
+	}
+}
@@ -0,0 +1,323 @@
+package csfloat
+
+import (
+	"context"
+	json "encoding/json/v2"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// OfferAction is what an OfferResponder does with an incoming offer.
+type OfferAction uint8
+
+const (
+	// OfferIgnore leaves the offer for a human to answer.
+	OfferIgnore OfferAction = iota
+	OfferAccept
+	OfferDecline
+	OfferCounter
+)
+
+func (action OfferAction) String() string {
+	switch action {
+	case OfferAccept:
+		return "accept"
+	case OfferDecline:
+		return "decline"
+	case OfferCounter:
+		return "counter"
+	default:
+		return "ignore"
+	}
+}
+
+func (action OfferAction) MarshalText() ([]byte, error) {
+	return []byte(action.String()), nil
+}
+
+// OfferDecision is the result of an OfferRule.
+type OfferDecision struct {
+	Action OfferAction
+	// CounterPrice is only used for OfferCounter.
+	CounterPrice uint
+	// Reason explains the decision in the audit log.
+	Reason string
+}
+
+// OfferRule decides on an incoming offer. If the rule doesn't apply to the
+// offer, it returns false and the next rule is asked.
+type OfferRule interface {
+	Decide(offer *Offer) (OfferDecision, bool)
+}
+
+// OfferRuleFunc adapts a function to an OfferRule.
+type OfferRuleFunc func(offer *Offer) (OfferDecision, bool)
+
+func (fn OfferRuleFunc) Decide(offer *Offer) (OfferDecision, bool) {
+	return fn(offer)
+}
+
+// AcceptAtRatio accepts offers of at least Ratio times the listing's price,
+// for example 0.95 for offers of at most 5% off. Like the other ratio rules,
+// it doesn't apply if the listing's price is unknown.
+type AcceptAtRatio struct {
+	Ratio float64
+}
+
+func (rule AcceptAtRatio) Decide(offer *Offer) (OfferDecision, bool) {
+	if offer.Contract.Price <= 0 {
+		return OfferDecision{}, false
+	}
+	threshold := uint(math.Ceil(float64(offer.Contract.Price) * rule.Ratio))
+	if offer.Price < threshold {
+		return OfferDecision{}, false
+	}
+	return OfferDecision{
+		Action: OfferAccept,
+		Reason: fmt.Sprintf("price %d is at least %v of listing price %d", offer.Price, rule.Ratio, offer.Contract.Price),
+	}, true
+}
+
+// AcceptAboveCostBasis accepts offers that exceed what we paid for the item
+// by at least Margin cents. CostBasis returns the amount paid, or false if
+// unknown, in which case the rule doesn't apply.
+type AcceptAboveCostBasis struct {
+	CostBasis func(listing *ActiveListing) (int, bool)
+	Margin    int
+}
+
+func (rule AcceptAboveCostBasis) Decide(offer *Offer) (OfferDecision, bool) {
+	costBasis, ok := rule.CostBasis(&offer.Contract)
+	if !ok || int(offer.Price) < costBasis+rule.Margin {
+		return OfferDecision{}, false
+	}
+	return OfferDecision{
+		Action: OfferAccept,
+		Reason: fmt.Sprintf("price %d covers cost basis %d plus margin %d", offer.Price, costBasis, rule.Margin),
+	}, true
+}
+
+// CounterAtRatio counters offers below Ratio times the listing's price with
+// exactly that price.
+type CounterAtRatio struct {
+	Ratio float64
+}
+
+func (rule CounterAtRatio) Decide(offer *Offer) (OfferDecision, bool) {
+	if offer.Contract.Price <= 0 {
+		return OfferDecision{}, false
+	}
+	price := uint(math.Round(float64(offer.Contract.Price) * rule.Ratio))
+	if offer.Price >= price {
+		return OfferDecision{}, false
+	}
+	return OfferDecision{
+		Action:       OfferCounter,
+		CounterPrice: price,
+		Reason:       fmt.Sprintf("countering at %v of listing price %d", rule.Ratio, offer.Contract.Price),
+	}, true
+}
+
+// DeclineBelowRatio declines offers below Ratio times the listing's price.
+type DeclineBelowRatio struct {
+	Ratio float64
+}
+
+func (rule DeclineBelowRatio) Decide(offer *Offer) (OfferDecision, bool) {
+	if offer.Contract.Price <= 0 {
+		return OfferDecision{}, false
+	}
+	floor := uint(math.Ceil(float64(offer.Contract.Price) * rule.Ratio))
+	if offer.Price >= floor {
+		return OfferDecision{}, false
+	}
+	return OfferDecision{
+		Action: OfferDecline,
+		Reason: fmt.Sprintf("price %d is below floor %d", offer.Price, floor),
+	}, true
+}
+
+// OfferAuditEntry records a single decision of an OfferResponder.
+type OfferAuditEntry struct {
+	Time           time.Time   `json:"time"`
+	OfferID        string      `json:"offer_id"`
+	ContractID     string      `json:"contract_id"`
+	MarketHashName string      `json:"market_hash_name"`
+	ListingPrice   int         `json:"listing_price"`
+	OfferPrice     uint        `json:"offer_price"`
+	Action         OfferAction `json:"action"`
+	CounterPrice   uint        `json:"counter_price,omitzero"`
+	Reason         string      `json:"reason"`
+	// DryRun is set if the decision wasn't applied.
+	DryRun bool `json:"dry_run,omitzero"`
+	// Error is set if applying the decision failed.
+	Error string `json:"error,omitzero"`
+}
+
+// OfferResponder answers incoming offers according to rules. For each offer,
+// the rules are asked in order and the first applicable decision is taken.
+// Offers no rule applies to are ignored, leaving them for a human. Only
+// offers from buyers on our listings are answered, counter offers by sellers
+// on items we bid on are left alone.
+type OfferResponder struct {
+	// Rules apply to all listings without ListingRules.
+	Rules []OfferRule
+	// ListingRules maps listing IDs to rules replacing Rules for that
+	// listing. Without any rules, offers on that listing are always ignored.
+	ListingRules map[string][]OfferRule
+	// DryRun only records the decisions, without applying them.
+	DryRun bool
+	// AuditLog receives every decision as a line of JSON, if set.
+	AuditLog io.Writer
+
+	// ignored holds the offers ignored by the previous run.
+	ignored map[string]struct{}
+}
+
+// decide returns the decision of the first applicable rule.
+func (responder *OfferResponder) decide(offer *Offer) OfferDecision {
+	rules, ok := responder.ListingRules[offer.ContractID]
+	if !ok {
+		rules = responder.Rules
+	}
+	for _, rule := range rules {
+		if decision, ok := rule.Decide(offer); ok {
+			return decision
+		}
+	}
+	return OfferDecision{Action: OfferIgnore, Reason: "no rule applies"}
+}
+
+// Respond decides on all active incoming offers once and applies the
+// decisions, unless DryRun is set. Between requests, the SuggestedWait of the
+// respective bucket is awaited. Failing to apply a decision doesn't stop
+// the run, the error is recorded in the entry instead. An error is only
+// returned if the offers can't be fetched or the audit log can't be written.
+//
+// Ignored offers stay active, so they are only recorded by the first run
+// ignoring them, not again by every further run of the same responder.
+// Offers cancelled because another offer on the listing was just accepted
+// are recorded as ignored as well.
+func (responder *OfferResponder) Respond(ctx context.Context, api *API) ([]OfferAuditEntry, error) {
+	// All offers are fetched first, as answering them removes them from the
+	// pages of active offers.
+	var offers []Offer
+	for offer, err := range api.AllOffers(ctx, OffersRequest{
+		Direction: Incoming,
+		States:    []OfferState{OfferActive},
+	}) {
+		if err != nil {
+			return nil, fmt.Errorf("error getting offers: %w", err)
+		}
+		offers = append(offers, offer)
+	}
+
+	var entries []OfferAuditEntry
+	ignored := make(map[string]struct{})
+	// Offers ignored before, but no longer active, are forgotten.
+	defer func() { responder.ignored = ignored }()
+	// Accepting an offer cancels all other offers on the listing, so sold
+	// maps listings to the accepted offer.
+	sold := make(map[string]string)
+	for _, offer := range offers {
+		// Accepting a seller's counter offer would buy the item.
+		if offer.Type != BuyerOffer {
+			continue
+		}
+
+		var decision OfferDecision
+		if acceptedId, ok := sold[offer.ContractID]; ok {
+			decision = OfferDecision{
+				Action: OfferIgnore,
+				Reason: fmt.Sprintf("listing sold via offer %s", acceptedId),
+			}
+		} else if decision = responder.decide(&offer); decision.Action == OfferIgnore {
+			ignored[offer.ID] = struct{}{}
+			if _, ok := responder.ignored[offer.ID]; ok {
+				continue
+			}
+		}
+
+		entry := OfferAuditEntry{
+			Time:           time.Now(),
+			OfferID:        offer.ID,
+			ContractID:     offer.ContractID,
+			MarketHashName: offer.Contract.Item.MarketHashName,
+			ListingPrice:   offer.Contract.Price,
+			OfferPrice:     offer.Price,
+			Action:         decision.Action,
+			CounterPrice:   decision.CounterPrice,
+			Reason:         decision.Reason,
+			DryRun:         responder.DryRun && decision.Action != OfferIgnore,
+		}
+		if !responder.DryRun {
+			if err := applyOfferDecision(ctx, api, offer.ID, decision); err != nil {
+				if ctx.Err() != nil {
+					return entries, err
+				}
+				entry.Error = err.Error()
+			}
+		}
+		// A dry run records the listing as sold as well, so it shows the
+		// same decisions as a real run.
+		if decision.Action == OfferAccept && entry.Error == "" {
+			sold[offer.ContractID] = offer.ID
+		}
+
+		entries = append(entries, entry)
+		if err := responder.audit(entry); err != nil {
+			return entries, err
+		}
+	}
+	return entries, nil
+}
+
+// Run calls Respond every interval until the context is done or Respond
+// returns an error. Transient errors are already retried according to the
+// RetryPolicy, so errors returned here usually need attention.
+func (responder *OfferResponder) Run(ctx context.Context, api *API, interval time.Duration) error {
+	for {
+		if _, err := responder.Respond(ctx, api); err != nil {
+			return err
+		}
+		if err := sleep(ctx, interval); err != nil {
+			return err
+		}
+	}
+}
+
+func (responder *OfferResponder) audit(entry OfferAuditEntry) error {
+	if responder.AuditLog == nil {
+		return nil
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("error encoding audit entry: %w", err)
+	}
+	if _, err := responder.AuditLog.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("error writing audit log: %w", err)
+	}
+	return nil
+}
+
+func applyOfferDecision(ctx context.Context, api *API, offerId string, decision OfferDecision) error {
+	var err error
+	switch decision.Action {
+	case OfferAccept:
+		if err = api.awaitSuggestedWait(ctx, RatelimitKeyAcceptOffer); err == nil {
+			_, err = api.AcceptOfferContext(ctx, offerId)
+		}
+	case OfferDecline:
+		if err = api.awaitSuggestedWait(ctx, RatelimitKeyDeclineOffer); err == nil {
+			_, err = api.DeclineOfferContext(ctx, offerId)
+		}
+	case OfferCounter:
+		if err = api.awaitSuggestedWait(ctx, RatelimitKeyCounterOffer); err == nil {
+			_, err = api.CounterOfferContext(ctx, offerId, decision.CounterPrice)
+		}
+	}
+	return err
+}
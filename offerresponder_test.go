@@ -0,0 +1,165 @@
+package csfloat_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	csfloat "github.com/Bios-Marcel/csfloat_go"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_OfferResponder(t *testing.T) {
+	ass := assert.New(t)
+	server, api := newFakeServer(t)
+	me := csfloat.Seller{SteamID: server.Me().SteamId}
+	var listings []csfloat.ActiveListing
+	for range 4 {
+		listings = append(listings, server.AddListing(csfloat.ActiveListing{Price: 1000, Seller: me}))
+	}
+	var offers []csfloat.Offer
+	for index, price := range []uint{960, 850, 400, 990, 970} {
+		offer, err := server.AddOffer(csfloat.Offer{ContractID: listings[index%4].ID, Price: price})
+		if !ass.NoError(err) {
+			return
+		}
+		offers = append(offers, offer)
+	}
+
+	var auditLog bytes.Buffer
+	responder := &csfloat.OfferResponder{
+		Rules: []csfloat.OfferRule{
+			csfloat.AcceptAtRatio{Ratio: 0.95},
+			csfloat.DeclineBelowRatio{Ratio: 0.5},
+			csfloat.CounterAtRatio{Ratio: 0.9},
+		},
+		ListingRules: map[string][]csfloat.OfferRule{
+			// Always answered by hand.
+			listings[3].ID: nil,
+		},
+		DryRun:   true,
+		AuditLog: &auditLog,
+	}
+
+	entries, err := responder.Respond(context.Background(), api)
+	if ass.NoError(err) && ass.Len(entries, 5) {
+		ass.Equal(offers[4].ID, entries[0].OfferID)
+		ass.Equal(csfloat.OfferAccept, entries[0].Action)
+		ass.True(entries[0].DryRun)
+		// Like in a real run, the other offer on the listing isn't accepted.
+		ass.Equal(offers[0].ID, entries[4].OfferID)
+		ass.Equal(csfloat.OfferIgnore, entries[4].Action)
+		ass.Contains(entries[4].Reason, offers[4].ID)
+	}
+	lines := strings.Split(strings.TrimSpace(auditLog.String()), "\n")
+	if ass.Len(lines, 5) {
+		ass.Contains(lines[0], `"action":"accept"`)
+		ass.Contains(lines[0], `"dry_run":true`)
+	}
+	for _, offer := range offers {
+		current, _ := server.Offer(offer.ID)
+		ass.Equal(csfloat.OfferActive, current.State)
+	}
+
+	// The offer on the listing answered by hand was already recorded as
+	// ignored, so it isn't recorded again.
+	responder.DryRun = false
+	entries, err = responder.Respond(context.Background(), api)
+	if !ass.NoError(err) || !ass.Len(entries, 4) {
+		return
+	}
+	for _, entry := range entries {
+		ass.Empty(entry.Error)
+	}
+	ass.Equal(offers[4].ID, entries[0].OfferID)
+	ass.Equal(csfloat.OfferAccept, entries[0].Action)
+	ass.Equal(csfloat.OfferDecline, entries[1].Action)
+	ass.Equal(csfloat.OfferCounter, entries[2].Action)
+	ass.Equal(uint(900), entries[2].CounterPrice)
+	// The other offer on the sold listing is recorded, but not answered.
+	ass.Equal(offers[0].ID, entries[3].OfferID)
+	ass.Equal(csfloat.OfferIgnore, entries[3].Action)
+	ass.Contains(entries[3].Reason, offers[4].ID)
+	ass.Len(strings.Split(strings.TrimSpace(auditLog.String()), "\n"), 9)
+
+	entries, err = responder.Respond(context.Background(), api)
+	ass.NoError(err)
+	ass.Empty(entries)
+
+	for index, state := range []csfloat.OfferState{
+		csfloat.OfferCancelled,
+		csfloat.OfferCountered,
+		csfloat.OfferDeclined,
+		csfloat.OfferActive,
+		csfloat.OfferAccepted,
+	} {
+		current, _ := server.Offer(offers[index].ID)
+		ass.Equal(state, current.State, "offer %d", index)
+	}
+}
+
+func Test_AcceptAboveCostBasis(t *testing.T) {
+	ass := assert.New(t)
+	rule := csfloat.AcceptAboveCostBasis{
+		CostBasis: func(listing *csfloat.ActiveListing) (int, bool) {
+			return 700, listing.Item.ID == "known"
+		},
+		Margin: 50,
+	}
+
+	offer := &csfloat.Offer{Price: 750, Contract: csfloat.ActiveListing{Price: 1000, Item: csfloat.Item{ID: "known"}}}
+	decision, ok := rule.Decide(offer)
+	ass.True(ok)
+	ass.Equal(csfloat.OfferAccept, decision.Action)
+
+	offer.Price = 749
+	_, ok = rule.Decide(offer)
+	ass.False(ok)
+
+	offer.Price = 900
+	offer.Contract.Item.ID = "unknown"
+	_, ok = rule.Decide(offer)
+	ass.False(ok)
+}
+
+func Test_RatioRulesWithoutListingPrice(t *testing.T) {
+	ass := assert.New(t)
+	offer := &csfloat.Offer{Price: 500}
+	for _, rule := range []csfloat.OfferRule{
+		csfloat.AcceptAtRatio{Ratio: 0.95},
+		csfloat.CounterAtRatio{Ratio: 0.9},
+		csfloat.DeclineBelowRatio{Ratio: 0.5},
+	} {
+		_, ok := rule.Decide(offer)
+		ass.False(ok, "%T", rule)
+	}
+}
+
+func Test_OfferResponderLeavesSellerOffers(t *testing.T) {
+	ass := assert.New(t)
+	server, api := newFakeServer(t)
+	listing := server.AddListing(csfloat.ActiveListing{Price: 1000})
+	// A counter offer by the seller to our offer, which we'd have to pay.
+	counter, err := server.AddOffer(csfloat.Offer{
+		ContractID: listing.ID,
+		Price:      990,
+		Type:       csfloat.SellerOffer,
+		BuyerID:    server.Me().SteamId,
+	})
+	if !ass.NoError(err) {
+		return
+	}
+	balance := server.Me().Balance
+
+	responder := &csfloat.OfferResponder{
+		Rules: []csfloat.OfferRule{csfloat.AcceptAtRatio{Ratio: 0.95}},
+	}
+	entries, err := responder.Respond(context.Background(), api)
+	ass.NoError(err)
+	ass.Empty(entries)
+
+	current, _ := server.Offer(counter.ID)
+	ass.Equal(csfloat.OfferActive, current.State)
+	ass.Equal(balance, server.Me().Balance)
+}
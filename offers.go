@@ -0,0 +1,248 @@
+package csfloat
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OfferState is the state of a price offer on a listing.
+type OfferState string
+
+const (
+	// OfferActive means the offer awaits a response of the receiver. It is
+	// the only state in which an offer can be accepted, declined or
+	// countered.
+	OfferActive OfferState = "active"
+	// OfferAccepted means the receiver accepted the offer, creating a trade
+	// at the offered price.
+	OfferAccepted OfferState = "accepted"
+	// OfferDeclined means the receiver declined the offer.
+	OfferDeclined OfferState = "declined"
+	// OfferCountered means the receiver answered with a counter offer, which
+	// is a new offer, referencing this one via ParentID.
+	OfferCountered OfferState = "countered"
+	// OfferCancelled means the sender withdrew the offer, or the listing was
+	// sold or unlisted in the meantime.
+	OfferCancelled OfferState = "cancelled"
+	// OfferExpired means nobody responded before ExpiresAt.
+	OfferExpired OfferState = "expired"
+)
+
+// Final reports whether the offer can't change anymore.
+func (state OfferState) Final() bool {
+	return state != OfferActive
+}
+
+// OfferType tells who sent an offer.
+type OfferType string
+
+const (
+	// BuyerOffer is sent by a buyer to the seller of a listing.
+	BuyerOffer OfferType = "buyer_offer"
+	// SellerOffer is a counter offer sent by the seller to the buyer.
+	SellerOffer OfferType = "seller_offer"
+)
+
+// Offer is a price offer on a listing. Not to be confused with Steam trade
+// offers, see PostNewOffer.
+type Offer struct {
+	ID         string     `json:"id"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	Type       OfferType  `json:"type"`
+	State      OfferState `json:"state"`
+	BuyerID    string     `json:"buyer_id"`
+	SellerID   string     `json:"seller_id"`
+	ContractID string     `json:"contract_id"`
+	// Contract is the listing, as of the time of the request.
+	Contract ActiveListing `json:"contract"`
+	Price    uint          `json:"price"`
+	// ParentID is set for counter offers, referencing the countered offer.
+	ParentID string `json:"parent_offer_id,omitempty"`
+}
+
+// SenderID returns the steam ID of whoever sent the offer.
+func (offer *Offer) SenderID() string {
+	if offer.Type == SellerOffer {
+		return offer.SellerID
+	}
+	return offer.BuyerID
+}
+
+type OfferResponse struct {
+	GenericResponse
+	Offer
+}
+
+func (response *OfferResponse) responseBody() any {
+	return response
+}
+
+type SendOfferRequest struct {
+	ContractID string `json:"contract_id"`
+	// Price has to be below the listing's price, but not by more than its
+	// MaxOfferDiscount allows.
+	Price uint `json:"price"`
+}
+
+// SendOffer offers to buy a listing at a lower price.
+func (api *API) SendOffer(request SendOfferRequest) (*OfferResponse, error) {
+	return api.SendOfferContext(context.Background(), request)
+}
+
+// SendOfferContext is like SendOffer, but uses ctx for the request.
+func (api *API) SendOfferContext(ctx context.Context, request SendOfferRequest) (*OfferResponse, error) {
+	return handleRequest(
+		ctx,
+		api,
+		RatelimitKeySendOffer,
+		api.httpClient,
+		http.MethodPost,
+		"/offers",
+		api.apiKey,
+		request,
+		nil,
+		&OfferResponse{},
+	)
+}
+
+// OfferDirection selects offers by whether we received or sent them.
+type OfferDirection string
+
+const (
+	Incoming OfferDirection = "received"
+	Outgoing OfferDirection = "sent"
+)
+
+type OffersRequest struct {
+	// Direction, default Incoming
+	Direction OfferDirection
+	// States, empty by default, not filtering
+	States []OfferState
+	// Page, default 0 (latest)
+	Page uint
+	// Limit, default 100
+	Limit uint
+}
+
+type OffersResponse struct {
+	GenericResponse
+	Offers []Offer `json:"offers"`
+	Count  uint    `json:"count"`
+}
+
+func (response *OffersResponse) responseBody() any {
+	return response
+}
+
+// Offers returns a page of our incoming or outgoing offers, newest first. Use
+// AllOffers to get all of them.
+func (api *API) Offers(request OffersRequest) (*OffersResponse, error) {
+	return api.OffersContext(context.Background(), request)
+}
+
+// OffersContext is like Offers, but uses ctx for the request.
+func (api *API) OffersContext(ctx context.Context, request OffersRequest) (*OffersResponse, error) {
+	if request.Limit == 0 {
+		request.Limit = 100
+	}
+	if request.Direction == "" {
+		request.Direction = Incoming
+	}
+
+	query := url.Values{}
+	query.Set("type", string(request.Direction))
+	if len(request.States) > 0 {
+		states := make([]string, 0, len(request.States))
+		for _, state := range request.States {
+			states = append(states, string(state))
+		}
+		query.Set("state", strings.Join(states, ","))
+	}
+	query.Set("page", strconv.FormatUint(uint64(request.Page), 10))
+	query.Set("limit", strconv.FormatUint(uint64(request.Limit), 10))
+
+	return handleRequest(
+		ctx,
+		api,
+		RatelimitKeyGetOffers,
+		api.httpClient,
+		http.MethodGet,
+		"/me/offers",
+		api.apiKey,
+		nil,
+		query,
+		&OffersResponse{},
+	)
+}
+
+// AcceptOffer accepts an incoming offer, creating a trade at the offered
+// price.
+func (api *API) AcceptOffer(offerId string) (*OfferResponse, error) {
+	return api.AcceptOfferContext(context.Background(), offerId)
+}
+
+// AcceptOfferContext is like AcceptOffer, but uses ctx for the request.
+func (api *API) AcceptOfferContext(ctx context.Context, offerId string) (*OfferResponse, error) {
+	return handleRequest(
+		ctx,
+		api,
+		RatelimitKeyAcceptOffer,
+		api.httpClient,
+		http.MethodPost,
+		"/offers/"+offerId+"/accept",
+		api.apiKey,
+		nil,
+		nil,
+		&OfferResponse{},
+	)
+}
+
+// DeclineOffer declines an incoming offer, or cancels an outgoing one.
+func (api *API) DeclineOffer(offerId string) (*OfferResponse, error) {
+	return api.DeclineOfferContext(context.Background(), offerId)
+}
+
+// DeclineOfferContext is like DeclineOffer, but uses ctx for the request.
+func (api *API) DeclineOfferContext(ctx context.Context, offerId string) (*OfferResponse, error) {
+	return handleRequest(
+		ctx,
+		api,
+		RatelimitKeyDeclineOffer,
+		api.httpClient,
+		http.MethodDelete,
+		"/offers/"+offerId,
+		api.apiKey,
+		nil,
+		nil,
+		&OfferResponse{},
+	)
+}
+
+// CounterOffer answers an incoming offer with a different price. The
+// returned offer is the counter offer, the original one is OfferCountered.
+func (api *API) CounterOffer(offerId string, price uint) (*OfferResponse, error) {
+	return api.CounterOfferContext(context.Background(), offerId, price)
+}
+
+// CounterOfferContext is like CounterOffer, but uses ctx for the request.
+func (api *API) CounterOfferContext(ctx context.Context, offerId string, price uint) (*OfferResponse, error) {
+	return handleRequest(
+		ctx,
+		api,
+		RatelimitKeyCounterOffer,
+		api.httpClient,
+		http.MethodPost,
+		"/offers/"+offerId+"/counter-offer",
+		api.apiKey,
+		map[string]any{
+			"price": price,
+		},
+		nil,
+		&OfferResponse{},
+	)
+}
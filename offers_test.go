@@ -0,0 +1,108 @@
+package csfloat_test
+
+import (
+	"context"
+	"testing"
+
+	csfloat "github.com/Bios-Marcel/csfloat_go"
+	"github.com/Bios-Marcel/csfloat_go/csfloattest"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_IncomingOffers(t *testing.T) {
+	ass := assert.New(t)
+	server, api := newFakeServer(t)
+	me := csfloat.Seller{SteamID: server.Me().SteamId}
+	countered := server.AddListing(csfloat.ActiveListing{Price: 1000, Seller: me})
+	accepted := server.AddListing(csfloat.ActiveListing{Price: 1000, Seller: me})
+	declined := server.AddListing(csfloat.ActiveListing{Price: 1000, Seller: me})
+	toCounter, _ := server.AddOffer(csfloat.Offer{ContractID: countered.ID, Price: 800})
+	toAccept, _ := server.AddOffer(csfloat.Offer{ContractID: accepted.ID, Price: 700})
+	toDecline, _ := server.AddOffer(csfloat.Offer{ContractID: declined.ID, Price: 100})
+
+	incoming, err := api.Offers(csfloat.OffersRequest{Direction: csfloat.Incoming})
+	if ass.NoError(err) && ass.Len(incoming.Offers, 3) {
+		ass.Equal(toDecline.ID, incoming.Offers[0].ID)
+		ass.Equal(csfloattest.OtherSteamID, incoming.Offers[0].SenderID())
+		ass.Equal(declined.ID, incoming.Offers[0].Contract.ID)
+	}
+	// Incoming is the default direction.
+	incoming, err = api.Offers(csfloat.OffersRequest{})
+	if ass.NoError(err) {
+		ass.Len(incoming.Offers, 3)
+	}
+
+	counter, err := api.CounterOffer(toCounter.ID, 900)
+	if ass.NoError(err) {
+		ass.Equal(csfloat.SellerOffer, counter.Type)
+		ass.Equal(toCounter.ID, counter.ParentID)
+		ass.Equal(csfloat.OfferActive, counter.State)
+	}
+	offer, _ := server.Offer(toCounter.ID)
+	ass.Equal(csfloat.OfferCountered, offer.State)
+	_, err = api.AcceptOffer(toCounter.ID)
+	ass.Error(err)
+
+	_, err = api.AcceptOffer(toAccept.ID)
+	ass.NoError(err)
+	trades, err := api.Trades(csfloat.TradesRequest{})
+	if ass.NoError(err) && ass.Len(trades.Trades, 1) {
+		ass.Equal(700, trades.Trades[0].Contract.Price)
+		ass.Equal(csfloattest.OtherSteamID, trades.Trades[0].BuyerId)
+	}
+
+	declinedOffer, err := api.DeclineOffer(toDecline.ID)
+	if ass.NoError(err) {
+		ass.Equal(csfloat.OfferDeclined, declinedOffer.State)
+		ass.True(declinedOffer.State.Final())
+	}
+
+	outgoing, err := api.Offers(csfloat.OffersRequest{Direction: csfloat.Outgoing})
+	if ass.NoError(err) && ass.Len(outgoing.Offers, 1) {
+		ass.Equal(counter.ID, outgoing.Offers[0].ID)
+	}
+}
+
+func Test_SendOffer(t *testing.T) {
+	ass := assert.New(t)
+	server, api := newFakeServer(t)
+	listing := server.AddListing(csfloat.ActiveListing{Price: 1000})
+
+	_, err := api.SendOffer(csfloat.SendOfferRequest{ContractID: listing.ID, Price: 1200})
+	ass.Error(err)
+
+	offer, err := api.SendOffer(csfloat.SendOfferRequest{ContractID: listing.ID, Price: 900})
+	if !ass.NoError(err) {
+		return
+	}
+	ass.Equal(csfloat.BuyerOffer, offer.Type)
+	ass.Equal(server.Me().SteamId, offer.SenderID())
+
+	cancelled, err := api.DeclineOffer(offer.ID)
+	if ass.NoError(err) {
+		ass.Equal(csfloat.OfferCancelled, cancelled.State)
+	}
+}
+
+func Test_AllOffers(t *testing.T) {
+	ass := assert.New(t)
+	server, api := newFakeServer(t)
+	listing := server.AddListing(csfloat.ActiveListing{Price: 1000, Seller: csfloat.Seller{SteamID: server.Me().SteamId}})
+	for range 5 {
+		server.AddOffer(csfloat.Offer{ContractID: listing.ID, Price: 800})
+	}
+	server.AddOffer(csfloat.Offer{ContractID: listing.ID, Price: 800, State: csfloat.OfferExpired})
+
+	var count int
+	for _, err := range api.AllOffers(context.Background(), csfloat.OffersRequest{
+		Direction: csfloat.Incoming,
+		States:    []csfloat.OfferState{csfloat.OfferActive},
+		Limit:     2,
+	}) {
+		if !ass.NoError(err) {
+			return
+		}
+		count++
+	}
+	ass.Equal(5, count)
+}
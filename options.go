@@ -0,0 +1,91 @@
+package csfloat
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultBaseURL is the base URL all endpoint paths are resolved against,
+// unless WithBaseURL is used.
+const DefaultBaseURL = "https://csfloat.com/api/v1"
+
+// Option configures an API created via New or NewWithHTTPClient.
+type Option func(*options)
+
+type options struct {
+	baseURL   string
+	userAgent string
+	headers   http.Header
+	timeout   time.Duration
+	transport http.RoundTripper
+	dialer    *net.Dialer
+}
+
+func newOptions(opts []Option) options {
+	options := options{
+		baseURL: DefaultBaseURL,
+		timeout: 15 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// WithBaseURL replaces DefaultBaseURL, for example to use a proxy or a fake
+// server in tests. The URL must include the API version path, such as
+// "http://localhost:8080/api/v1".
+func WithBaseURL(baseURL string) Option {
+	return func(options *options) {
+		options.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// WithUserAgent sets the User-Agent header of all requests.
+func WithUserAgent(userAgent string) Option {
+	return func(options *options) {
+		options.userAgent = userAgent
+	}
+}
+
+// WithHeaders adds the given headers to all requests. Headers required by
+// the API, such as Authorization, take precedence. Calling it multiple times
+// merges the headers.
+func WithHeaders(headers http.Header) Option {
+	return func(options *options) {
+		if options.headers == nil {
+			options.headers = make(http.Header, len(headers))
+		}
+		for key, values := range headers {
+			for _, value := range values {
+				options.headers.Add(key, value)
+			}
+		}
+	}
+}
+
+// WithTimeout sets the overall timeout of each request attempt, including
+// reading the response. The default is 15 seconds; 0 means no timeout.
+func WithTimeout(timeout time.Duration) Option {
+	return func(options *options) {
+		options.timeout = timeout
+	}
+}
+
+// WithTransport replaces the default transport. If set, WithDialer has no
+// effect.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(options *options) {
+		options.transport = transport
+	}
+}
+
+// WithDialer replaces the dialer of the default transport. This can for
+// example be used to force a certain IP version or local address.
+func WithDialer(dialer *net.Dialer) Option {
+	return func(options *options) {
+		options.dialer = dialer
+	}
+}
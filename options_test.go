@@ -0,0 +1,44 @@
+package csfloat_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	csfloat "github.com/Bios-Marcel/csfloat_go"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Options(t *testing.T) {
+	ass := assert.New(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /gateway/v1/listings/{id}", func(w http.ResponseWriter, r *http.Request) {
+		ass.Equal("my-bot/1.0", r.UserAgent())
+		ass.Equal([]string{"a", "b"}, r.Header.Values("X-Custom"))
+		ass.Equal("key", r.Header.Get("Authorization"))
+		writeJSON(w, `{"id":"`+r.PathValue("id")+`"}`)
+	})
+	mux.HandleFunc("GET /gateway/v1/me", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		writeJSON(w, `{}`)
+	})
+	server := newTestServer(t, mux)
+
+	api := csfloat.New("key",
+		csfloat.WithBaseURL(server.URL+"/gateway/v1/"),
+		csfloat.WithTransport(server.Client().Transport),
+		csfloat.WithUserAgent("my-bot/1.0"),
+		csfloat.WithHeaders(http.Header{"X-Custom": {"a"}}),
+		csfloat.WithHeaders(http.Header{"X-Custom": {"b"}}),
+		csfloat.WithTimeout(50*time.Millisecond),
+	)
+
+	response, err := api.Listing("123")
+	if ass.NoError(err) {
+		ass.Equal("123", response.Item.ID)
+	}
+
+	_, err = api.Me()
+	ass.Error(err)
+}
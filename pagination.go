@@ -0,0 +1,211 @@
+package csfloat
+
+import (
+	"context"
+	"iter"
+)
+
+// iterate yields all items returned by repeated calls to fetch, starting at
+// the given position, until fetch reports that there are no more items.
+// Between calls, it waits for the bucket's SuggestedWait. Since new items may
+// be added while iterating, shifting items onto the next page, items are
+// de-duplicated via id. An empty page or a position that was already fetched
+// also ends the iteration, as the endpoint then most likely ignores the
+// position.
+func iterate[T any, P comparable](
+	ctx context.Context,
+	api *API,
+	bucketKey RatelimitBucketKey,
+	start P,
+	id func(T) string,
+	fetch func(ctx context.Context, position P) (items []T, next P, more bool, err error),
+) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		seen := make(map[string]struct{})
+		fetched := make(map[P]struct{})
+		position := start
+		for first := true; ; first = false {
+			if !first {
+				if err := api.awaitSuggestedWait(ctx, bucketKey); err != nil {
+					var zero T
+					yield(zero, err)
+					return
+				}
+			}
+
+			items, next, more, err := fetch(ctx, position)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			fetched[position] = struct{}{}
+
+			for _, item := range items {
+				if _, ok := seen[id(item)]; ok {
+					continue
+				}
+				seen[id(item)] = struct{}{}
+				if !yield(item, nil) {
+					return
+				}
+			}
+
+			if _, ok := fetched[next]; !more || len(items) == 0 || ok {
+				return
+			}
+			position = next
+		}
+	}
+}
+
+// paginate iterates over all items of a page based endpoint. It stops once
+// count items have been reached or a page is empty.
+func paginate[T any](
+	ctx context.Context,
+	api *API,
+	bucketKey RatelimitBucketKey,
+	startPage uint,
+	limit uint,
+	id func(T) string,
+	fetch func(ctx context.Context, page uint) (items []T, count uint, err error),
+) iter.Seq2[T, error] {
+	return iterate(ctx, api, bucketKey, startPage, id,
+		func(ctx context.Context, page uint) ([]T, uint, bool, error) {
+			items, count, err := fetch(ctx, page)
+			return items, page + 1, (page+1)*limit < count, err
+		})
+}
+
+// AllTrades iterates over all trades matching the request, starting at
+// request.Page. If an error occurs, it is yielded and iteration stops.
+func (api *API) AllTrades(ctx context.Context, request TradesRequest) iter.Seq2[Trade, error] {
+	if request.Limit == 0 {
+		request.Limit = 100
+	}
+	return paginate(ctx, api, RatelimitKeyGetTrades, request.Page, request.Limit,
+		func(trade Trade) string { return trade.ID },
+		func(ctx context.Context, page uint) ([]Trade, uint, error) {
+			request := request
+			request.Page = page
+			response, err := api.TradesContext(ctx, request)
+			if err != nil {
+				return nil, 0, err
+			}
+			return response.Trades, response.Count, nil
+		})
+}
+
+// AllTransactions iterates over all transactions matching the request,
+// starting at request.Page. If an error occurs, it is yielded and iteration
+// stops.
+func (api *API) AllTransactions(ctx context.Context, request TransactionsRequest) iter.Seq2[Transaction, error] {
+	if request.Limit == 0 {
+		request.Limit = 100
+	}
+	return paginate(ctx, api, RatelimitKeyGetTransactions, request.Page, request.Limit,
+		func(transaction Transaction) string { return transaction.ID },
+		func(ctx context.Context, page uint) ([]Transaction, uint, error) {
+			request := request
+			request.Page = page
+			response, err := api.TransactionsContext(ctx, request)
+			if err != nil {
+				return nil, 0, err
+			}
+			return response.Transactions, response.Count, nil
+		})
+}
+
+// AllListings iterates over all listings matching the request, starting at
+// request.Cursor. Listings that move between pages while iterating, for
+// example due to price changes, are only yielded once. If an error occurs, it
+// is yielded and iteration stops.
+func (api *API) AllListings(ctx context.Context, request ListingsRequest) iter.Seq2[*ActiveListing, error] {
+	return iterate(ctx, api, RatelimitKeyGetListings, request.Cursor,
+		func(listing *ActiveListing) string { return listing.ID },
+		func(ctx context.Context, cursor string) ([]*ActiveListing, string, bool, error) {
+			request := request
+			request.Cursor = cursor
+			response, err := api.ListingsContext(ctx, request)
+			if err != nil {
+				return nil, "", false, err
+			}
+			return response.Data, response.Cursor, response.Cursor != "", nil
+		})
+}
+
+// AllStall iterates over all listings in the given user's stall, starting at
+// request.Cursor. If an error occurs, it is yielded and iteration stops.
+func (api *API) AllStall(ctx context.Context, steamId string, request StallRequest) iter.Seq2[ActiveListing, error] {
+	return iterate(ctx, api, RatelimitKeyGetStall, request.Cursor,
+		func(listing ActiveListing) string { return listing.ID },
+		func(ctx context.Context, cursor string) ([]ActiveListing, string, bool, error) {
+			request := request
+			request.Cursor = cursor
+			response, err := api.StallPageContext(ctx, steamId, request)
+			if err != nil {
+				return nil, "", false, err
+			}
+			return response.Items, response.Cursor, response.Cursor != "", nil
+		})
+}
+
+// AllInventory iterates over all items of the Steam inventory, starting at
+// request.Offset. The inventory has no total count, so iteration stops at the
+// first page that isn't full. If an error occurs, it is yielded and iteration
+// stops.
+func (api *API) AllInventory(ctx context.Context, request InventoryRequest) iter.Seq2[InventoryItem, error] {
+	if request.Limit == 0 {
+		request.Limit = 40
+	}
+	return iterate(ctx, api, RatelimitKeyGetInventory, request.Offset,
+		func(item InventoryItem) string { return item.ID },
+		func(ctx context.Context, offset uint) ([]InventoryItem, uint, bool, error) {
+			request := request
+			request.Offset = offset
+			response, err := api.InventoryPageContext(ctx, request)
+			if err != nil {
+				return nil, 0, false, err
+			}
+			count := uint(len(response.Data))
+			return response.Data, offset + count, count >= request.Limit, nil
+		})
+}
+
+// AllMyBuyOrders iterates over all of our own buy orders, starting at
+// request.Page. If an error occurs, it is yielded and iteration stops.
+func (api *API) AllMyBuyOrders(ctx context.Context, request MyBuyOrdersRequest) iter.Seq2[ItemBuyOrder, error] {
+	if request.Limit == 0 {
+		request.Limit = 100
+	}
+	return paginate(ctx, api, RatelimitKeyGetMyBuyOrders, request.Page, request.Limit,
+		func(order ItemBuyOrder) string { return order.ID },
+		func(ctx context.Context, page uint) ([]ItemBuyOrder, uint, error) {
+			request := request
+			request.Page = page
+			response, err := api.MyBuyOrdersContext(ctx, request)
+			if err != nil {
+				return nil, 0, err
+			}
+			return response.Orders, response.Count, nil
+		})
+}
+
+// AllOffers iterates over all offers matching the request, starting at
+// request.Page. If an error occurs, it is yielded and iteration stops.
+func (api *API) AllOffers(ctx context.Context, request OffersRequest) iter.Seq2[Offer, error] {
+	if request.Limit == 0 {
+		request.Limit = 100
+	}
+	return paginate(ctx, api, RatelimitKeyGetOffers, request.Page, request.Limit,
+		func(offer Offer) string { return offer.ID },
+		func(ctx context.Context, page uint) ([]Offer, uint, error) {
+			request := request
+			request.Page = page
+			response, err := api.OffersContext(ctx, request)
+			if err != nil {
+				return nil, 0, err
+			}
+			return response.Offers, response.Count, nil
+		})
+}
@@ -0,0 +1,221 @@
+package csfloat_test
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	csfloat "github.com/Bios-Marcel/csfloat_go"
+	"github.com/Bios-Marcel/csfloat_go/csfloattest"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_AllTrades(t *testing.T) {
+	ass := assert.New(t)
+	server, api := newFakeServer(t)
+	now := time.Now()
+	for i := range 250 {
+		server.AddTrade(csfloat.Trade{
+			ID:        strconv.Itoa(i),
+			CreatedAt: now.Add(-time.Duration(i) * time.Minute),
+			State:     csfloat.Verified,
+		})
+	}
+
+	var ids []string
+	for trade, err := range api.AllTrades(context.Background(), csfloat.TradesRequest{}) {
+		if !ass.NoError(err) {
+			break
+		}
+		ids = append(ids, trade.ID)
+	}
+	if ass.Len(ids, 250) {
+		ass.Equal("0", ids[0])
+		ass.Equal("249", ids[249])
+	}
+
+	var count int
+	for range api.AllTrades(context.Background(), csfloat.TradesRequest{Limit: 10, Page: 20}) {
+		count++
+	}
+	ass.Equal(50, count)
+
+	count = 0
+	for range api.AllTrades(context.Background(), csfloat.TradesRequest{Limit: 10}) {
+		count++
+		if count == 15 {
+			break
+		}
+	}
+	ass.Equal(15, count)
+}
+
+func Test_AllTransactionsError(t *testing.T) {
+	ass := assert.New(t)
+	server, api := newFakeServer(t)
+	for range 5 {
+		server.AddTransaction(csfloat.Transaction{CreatedAt: time.Now()})
+	}
+	server.FailNext(csfloat.RatelimitKeyGetTransactions, csfloattest.Unauthorized)
+
+	var yielded int
+	var errs []error
+	for _, err := range api.AllTransactions(context.Background(), csfloat.TransactionsRequest{Limit: 2}) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		yielded++
+	}
+	ass.Zero(yielded)
+	if ass.Len(errs, 1) {
+		ass.ErrorIs(errs[0], csfloat.ErrUnauthorized)
+	}
+}
+
+func Test_AllListings(t *testing.T) {
+	ass := assert.New(t)
+	server, api := newFakeServer(t)
+	now := time.Now()
+	for i := range 25 {
+		server.AddListing(csfloat.ActiveListing{
+			Price:     100 + i,
+			CreatedAt: now.Add(-time.Duration(i) * time.Minute),
+		})
+	}
+
+	response, err := api.Listings(csfloat.ListingsRequest{Limit: 10})
+	if ass.NoError(err) {
+		ass.Len(response.Data, 10)
+		ass.NotEmpty(response.Cursor)
+	}
+
+	seen := make(map[string]int)
+	for listing, err := range api.AllListings(context.Background(), csfloat.ListingsRequest{Limit: 10}) {
+		if !ass.NoError(err) {
+			break
+		}
+		seen[listing.ID]++
+		if len(seen) == 5 {
+			// New listings push the ones we've already seen onto the next
+			// page.
+			for range 3 {
+				server.AddListing(csfloat.ActiveListing{Price: 50, CreatedAt: now.Add(time.Minute)})
+			}
+		}
+	}
+	ass.Len(seen, 25)
+	for id, count := range seen {
+		ass.Equal(1, count, id)
+	}
+}
+
+func Test_AllListingsDuplicatePage(t *testing.T) {
+	ass := assert.New(t)
+	pages := map[string]string{
+		"":  `{"cursor":"a","data":[{"id":"1"},{"id":"2"}]}`,
+		"a": `{"cursor":"b","data":[{"id":"1"},{"id":"2"}]}`,
+		"b": `{"cursor":"b","data":[{"id":"3"}]}`,
+	}
+	var requests int
+	api := newTestAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("X-Ratelimit-Limit", "100")
+		w.Header().Set("X-Ratelimit-Remaining", "99")
+		w.Header().Set("X-Ratelimit-Reset", "0")
+		w.Write([]byte(pages[r.URL.Query().Get("cursor")]))
+	}))
+
+	var ids []string
+	for listing, err := range api.AllListings(context.Background(), csfloat.ListingsRequest{}) {
+		if !ass.NoError(err) {
+			break
+		}
+		ids = append(ids, listing.ID)
+	}
+	// A page of known listings doesn't end the iteration, but a repeated
+	// cursor does.
+	ass.Equal([]string{"1", "2", "3"}, ids)
+	ass.Equal(3, requests)
+}
+
+func Test_AllStall(t *testing.T) {
+	ass := assert.New(t)
+	server, api := newFakeServer(t)
+	me := server.Me()
+	for i := range 95 {
+		listingType := csfloat.BuyNow
+		if i%5 == 0 {
+			listingType = csfloat.Auction
+		}
+		server.AddListing(csfloat.ActiveListing{
+			Price:  100 + i,
+			Type:   listingType,
+			Seller: csfloat.Seller{SteamID: me.SteamId},
+		})
+	}
+
+	stall, err := api.Stall(me.SteamId)
+	if ass.NoError(err) {
+		ass.Len(stall.Items, 40)
+		ass.Equal(95, stall.Count)
+		ass.NotEmpty(stall.Cursor)
+	}
+
+	var prices []int
+	for listing, err := range api.AllStall(context.Background(), me.SteamId, csfloat.StallRequest{
+		SortBy: csfloat.HighestPrice,
+		Type:   csfloat.BuyNow,
+	}) {
+		if !ass.NoError(err) {
+			break
+		}
+		prices = append(prices, listing.Price)
+	}
+	if ass.Len(prices, 76) {
+		ass.Equal(194, prices[0])
+		ass.Equal(101, prices[75])
+	}
+}
+
+func Test_AllInventory(t *testing.T) {
+	ass := assert.New(t)
+	server, api := newFakeServer(t)
+	for i := range 45 {
+		server.AddInventoryItem(csfloat.InventoryItem{Item: csfloat.Item{ID: strconv.Itoa(1000 + i)}})
+	}
+
+	page, err := api.InventoryPage(csfloat.InventoryRequest{Limit: 10, Offset: 40})
+	if ass.NoError(err) {
+		ass.Len(page.Data, 5)
+	}
+
+	var count int
+	for _, err := range api.AllInventory(context.Background(), csfloat.InventoryRequest{Limit: 15}) {
+		if !ass.NoError(err) {
+			break
+		}
+		count++
+	}
+	ass.Equal(45, count)
+}
+
+func Test_AllMyBuyOrders(t *testing.T) {
+	ass := assert.New(t)
+	server, api := newFakeServer(t)
+	server.AddBuyOrder(csfloat.ItemBuyOrder{MarketHashName: "AK-47 | Redline (Field-Tested)", Price: 1000, Quantity: 1})
+	for range 5 {
+		server.AddMyBuyOrder(csfloat.ItemBuyOrder{MarketHashName: "AK-47 | Redline (Field-Tested)", Price: 900, Quantity: 1})
+	}
+
+	var ids []string
+	for order, err := range api.AllMyBuyOrders(context.Background(), csfloat.MyBuyOrdersRequest{Limit: 2}) {
+		if !ass.NoError(err) {
+			return
+		}
+		ids = append(ids, order.ID)
+	}
+	ass.Len(ids, 5)
+}
@@ -0,0 +1,36 @@
+package csfloat
+
+import (
+	"fmt"
+	"strings"
+)
+
+// formatPlan prints one line per change, followed by the summary, such as
+// "1 to create, 2 to update, 0 to delete". Without changes, it prints
+// "No changes." instead.
+func formatPlan[C fmt.Stringer](changes []C, summary string) string {
+	if len(changes) == 0 {
+		return "No changes.\n"
+	}
+
+	var builder strings.Builder
+	for _, change := range changes {
+		builder.WriteString(change.String())
+		builder.WriteByte('\n')
+	}
+	fmt.Fprintf(&builder, "\nPlan: %s.\n", summary)
+	return builder.String()
+}
+
+// applyPlan applies the changes in order. On the first error, it stops and
+// returns the changes applied so far along with the error.
+func applyPlan[C fmt.Stringer](changes []C, apply func(change C) error) ([]C, error) {
+	applied := make([]C, 0, len(changes))
+	for _, change := range changes {
+		if err := apply(change); err != nil {
+			return applied, fmt.Errorf("error applying %q: %w", change.String(), err)
+		}
+		applied = append(applied, change)
+	}
+	return applied, nil
+}
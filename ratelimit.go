@@ -1,9 +1,11 @@
 package csfloat
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"strconv"
+	"sync"
 	"time"
 )
 
@@ -37,6 +39,17 @@ const (
 	RatelimitKeyCreateListing          RatelimitBucketKey = "create_listing"
 	RatelimitKeyCreateBuyOrder         RatelimitBucketKey = "create_buy_order"
 	RatelimitKeyDeleteBuyOrder         RatelimitBucketKey = "delete_buy_order"
+	RatelimitKeyGetMyBuyOrders         RatelimitBucketKey = "get_my_buy_orders"
+	RatelimitKeyUpdateBuyOrder         RatelimitBucketKey = "update_buy_order"
+	RatelimitKeyBulkDeleteBuyOrders    RatelimitBucketKey = "bulk_delete_buy_orders"
+	RatelimitKeyPlaceBid               RatelimitBucketKey = "place_bid"
+	RatelimitKeyGetBids                RatelimitBucketKey = "get_bids"
+	RatelimitKeyGetMyBids              RatelimitBucketKey = "get_my_bids"
+	RatelimitKeySendOffer              RatelimitBucketKey = "create_offer"
+	RatelimitKeyGetOffers              RatelimitBucketKey = "get_offers"
+	RatelimitKeyAcceptOffer            RatelimitBucketKey = "accept_offer"
+	RatelimitKeyDeclineOffer           RatelimitBucketKey = "decline_offer"
+	RatelimitKeyCounterOffer           RatelimitBucketKey = "counter_offer"
 )
 
 type Ratelimits struct {
@@ -47,17 +60,98 @@ type Ratelimits struct {
 }
 
 // BucketRatelimits returns the most recent ratelimit data for the given bucket,
-// or nil if no request has been made for that bucket yet.
+// or nil if no request has been made for that bucket yet. The result is a copy
+// and can be retained by the caller.
 func (api *API) BucketRatelimits(key RatelimitBucketKey) *Ratelimits {
-	return api.ratelimits[key]
+	api.ratelimitsLock.RLock()
+	defer api.ratelimitsLock.RUnlock()
+
+	ratelimits, ok := api.ratelimits[key]
+	if !ok {
+		return nil
+	}
+	ratelimitsCopy := *ratelimits
+	return &ratelimitsCopy
+}
+
+// RatelimitSnapshot is a consistent copy of all known ratelimit data, taken at
+// a single point in time.
+type RatelimitSnapshot struct {
+	// Buckets contains an entry for every bucket that has been requested at
+	// least once.
+	Buckets map[RatelimitBucketKey]Ratelimits
+	// IP contains the IP-based ratelimits for both IPv4 and IPv6.
+	IP map[AddressFamily]IPRatelimits
+}
+
+// Snapshot returns a copy of the ratelimit data of all buckets. Since all
+// buckets are copied at once, the data is consistent across buckets.
+func (api *API) Snapshot() RatelimitSnapshot {
+	api.ratelimitsLock.RLock()
+	defer api.ratelimitsLock.RUnlock()
+
+	now := time.Now()
+	snapshot := RatelimitSnapshot{
+		Buckets: make(map[RatelimitBucketKey]Ratelimits, len(api.ratelimits)),
+		IP: map[AddressFamily]IPRatelimits{
+			IPv4: api.ipRatelimits(IPv4, now),
+			IPv6: api.ipRatelimits(IPv6, now),
+		},
+	}
+	for key, ratelimits := range api.ratelimits {
+		snapshot.Buckets[key] = *ratelimits
+	}
+	return snapshot
+}
+
+type ratelimitSubscription struct {
+	updates chan Ratelimits
+}
+
+// SubscribeRatelimits returns a channel that receives the ratelimit data of
+// the given bucket every time a response for that bucket arrives. The channel
+// only buffers the latest update, so slow receivers skip intermediate values
+// instead of blocking requests. The returned function unsubscribes and closes
+// the channel; it is safe to call multiple times.
+func (api *API) SubscribeRatelimits(key RatelimitBucketKey) (<-chan Ratelimits, func()) {
+	subscription := &ratelimitSubscription{
+		updates: make(chan Ratelimits, 1),
+	}
+
+	api.ratelimitsLock.Lock()
+	subscriptions, ok := api.ratelimitSubscribers[key]
+	if !ok {
+		subscriptions = make(map[*ratelimitSubscription]struct{})
+		api.ratelimitSubscribers[key] = subscriptions
+	}
+	subscriptions[subscription] = struct{}{}
+	api.ratelimitsLock.Unlock()
+
+	unsubscribe := sync.OnceFunc(func() {
+		api.ratelimitsLock.Lock()
+		defer api.ratelimitsLock.Unlock()
+
+		delete(api.ratelimitSubscribers[key], subscription)
+		close(subscription.updates)
+	})
+	return subscription.updates, unsubscribe
 }
 
 // IsGloballyRatelimited reports whether we appear to be hitting a global (cross-
-// endpoint) ratelimit. It detects this by checking if any bucket's remaining
-// count dropped from to 0 and the reset time is in the future and at most 5
-// minutes away (the known global reset window).
+// endpoint) ratelimit. This is the case if the IP-based ratelimit of any
+// address family with a known limit is used up. Additionally, it checks if any
+// bucket's remaining count dropped from to 0 and the reset time is in the
+// future and at most 5 minutes away (the known global reset window).
 func (api *API) IsGloballyRatelimited() bool {
+	api.ratelimitsLock.RLock()
+	defer api.ratelimitsLock.RUnlock()
+
 	now := time.Now()
+	for _, family := range []AddressFamily{IPv4, IPv6} {
+		if remaining, known := api.ipRatelimits(family, now).Remaining(); known && remaining == 0 {
+			return true
+		}
+	}
 	for _, entry := range api.ratelimits {
 		if entry != nil && entry.Remaining == 0 {
 			resetIn := entry.Reset.Sub(now)
@@ -69,8 +163,162 @@ func (api *API) IsGloballyRatelimited() bool {
 	return false
 }
 
+// RatelimitMode controls whether requests are held back on the client until
+// their bucket has budget left.
+type RatelimitMode uint8
+
+const (
+	// RatelimitModeOff sends requests right away, leaving it up to the caller
+	// to respect the ratelimits. This is the default.
+	RatelimitModeOff RatelimitMode = iota
+	// RatelimitModeWait blocks requests until the bucket has budget left or
+	// the request context is done.
+	RatelimitModeWait
+	// RatelimitModeFailFast returns a *RatelimitError instead of waiting.
+	RatelimitModeFailFast
+)
+
+// RatelimitError is returned by requests in RatelimitModeFailFast, if sending
+// the request right away would exceed the bucket's budget.
+type RatelimitError struct {
+	Bucket RatelimitBucketKey
+	// RetryAt is the earliest time at which the request may be sent.
+	RetryAt time.Time
+}
+
+func (err *RatelimitError) Error() string {
+	return fmt.Sprintf("ratelimit for bucket %s exhausted, retry at %s",
+		err.Bucket, err.RetryAt.Format(time.RFC3339))
+}
+
+// SetRatelimitMode sets the mode for a single bucket, overriding the default
+// mode.
+func (api *API) SetRatelimitMode(key RatelimitBucketKey, mode RatelimitMode) {
+	api.ratelimitsLock.Lock()
+	defer api.ratelimitsLock.Unlock()
+
+	api.ratelimitModes[key] = mode
+}
+
+// SetDefaultRatelimitMode sets the mode for all buckets that have no mode set
+// via SetRatelimitMode.
+func (api *API) SetDefaultRatelimitMode(mode RatelimitMode) {
+	api.ratelimitsLock.Lock()
+	defer api.ratelimitsLock.Unlock()
+
+	api.defaultRatelimitMode = mode
+}
+
+// ratelimitPacing keeps track of the requests that were let through since the
+// last ratelimit update, so that concurrent requests are spread out evenly.
+type ratelimitPacing struct {
+	// next is the earliest time at which the next request may be sent.
+	next time.Time
+	// reserved is the amount of requests let through since the last update.
+	reserved uint
+}
+
+// awaitRatelimit blocks until a request for the given bucket may be sent,
+// according to the bucket's mode. The budget is spread evenly until the
+// bucket resets, just like Ratelimits.SuggestedWait suggests. Buckets
+// without any ratelimit data yet are never held back.
+func (api *API) awaitRatelimit(ctx context.Context, key RatelimitBucketKey) error {
+	api.ratelimitsLock.Lock()
+
+	mode, ok := api.ratelimitModes[key]
+	if !ok {
+		mode = api.defaultRatelimitMode
+	}
+	ratelimits := api.ratelimits[key]
+	if mode == RatelimitModeOff || ratelimits == nil {
+		api.ratelimitsLock.Unlock()
+		return nil
+	}
+
+	pacing, ok := api.ratelimitPacing[key]
+	if !ok {
+		pacing = &ratelimitPacing{}
+		api.ratelimitPacing[key] = pacing
+	}
+
+	now := time.Now()
+	sendAt := now
+	// Once the reset has passed, our data is outdated and we can't know
+	// anything, so we simply let requests through until we learn more.
+	if now.Before(ratelimits.Reset) {
+		if pacing.reserved >= ratelimits.Remaining {
+			sendAt = ratelimits.Reset
+		} else if pacing.next.After(now) {
+			sendAt = pacing.next
+		}
+	}
+
+	if sendAt.After(now) && mode == RatelimitModeFailFast {
+		api.ratelimitsLock.Unlock()
+		return &RatelimitError{Bucket: key, RetryAt: sendAt}
+	}
+
+	if sendAt.Before(ratelimits.Reset) {
+		left := ratelimits.Remaining - pacing.reserved
+		pacing.next = sendAt.Add(ratelimits.Reset.Sub(sendAt) / time.Duration(left))
+		pacing.reserved++
+	} else {
+		pacing.next = sendAt
+	}
+	api.ratelimitsLock.Unlock()
+
+	if wait := time.Until(sendAt); wait > 0 {
+		return sleep(ctx, wait)
+	}
+	return nil
+}
+
+// awaitSuggestedWait blocks until the SuggestedWait of the given bucket has
+// passed or the context is done.
+func (api *API) awaitSuggestedWait(ctx context.Context, key RatelimitBucketKey) error {
+	ratelimits := api.BucketRatelimits(key)
+	if ratelimits == nil {
+		return nil
+	}
+	return sleep(ctx, time.Until(ratelimits.SuggestedWait))
+}
+
+// sleep blocks for the given duration or until the context is done.
+func sleep(ctx context.Context, duration time.Duration) error {
+	if duration <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
 func (api *API) updateRatelimits(key RatelimitBucketKey, ratelimits *Ratelimits) {
+	api.ratelimitsLock.Lock()
+	defer api.ratelimitsLock.Unlock()
+
 	api.ratelimits[key] = ratelimits
+	// The server data now accounts for all requests we have let through so
+	// far, except for the ones still in flight.
+	if pacing, ok := api.ratelimitPacing[key]; ok {
+		pacing.reserved = 0
+		pacing.next = ratelimits.SuggestedWait
+	}
+	for subscription := range api.ratelimitSubscribers[key] {
+		// Drop the previous update if it hasn't been received yet. We are the
+		// only sender and hold the lock, so the send can't block afterwards.
+		select {
+		case <-subscription.updates:
+		default:
+		}
+		subscription.updates <- *ratelimits
+	}
 }
 
 // ratelimitsFrom parses the headers to get ratelimiting. It does NOT consume
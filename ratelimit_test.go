@@ -0,0 +1,141 @@
+package csfloat_test
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	csfloat "github.com/Bios-Marcel/csfloat_go"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RatelimitsConcurrent(t *testing.T) {
+	ass := assert.New(t)
+
+	var remaining atomic.Int64
+	remaining.Store(1000)
+	reset := time.Now().Add(time.Hour).Unix()
+	api := newTestAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Ratelimit-Limit", "1000")
+		w.Header().Set("X-Ratelimit-Remaining", strconv.FormatInt(remaining.Add(-1), 10))
+		w.Header().Set("X-Ratelimit-Reset", strconv.FormatInt(reset, 10))
+		w.Write([]byte(`{"user":{}}`))
+	}))
+
+	updates, unsubscribe := api.SubscribeRatelimits(csfloat.RatelimitKeyGetMe)
+
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := api.Me()
+			ass.NoError(err)
+			api.Snapshot()
+			api.IsGloballyRatelimited()
+		}()
+	}
+	wg.Wait()
+
+	select {
+	case update := <-updates:
+		ass.Equal(uint(1000), update.Limit)
+	default:
+		t.Error("expected a ratelimit update")
+	}
+
+	snapshot := api.Snapshot()
+	ass.Contains(snapshot.Buckets, csfloat.RatelimitKeyGetMe)
+	ass.Equal(uint(1000), snapshot.Buckets[csfloat.RatelimitKeyGetMe].Limit)
+
+	unsubscribe()
+	unsubscribe()
+	_, open := <-updates
+	ass.False(open)
+}
+
+func Test_RatelimitModes(t *testing.T) {
+	ass := assert.New(t)
+
+	reset := time.Now().Add(time.Hour)
+	var requests atomic.Int64
+	api := newTestAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("X-Ratelimit-Limit", "10")
+		w.Header().Set("X-Ratelimit-Remaining", "0")
+		w.Header().Set("X-Ratelimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+		w.Write([]byte(`{"user":{}}`))
+	}))
+
+	// No data yet, so the first request always goes through.
+	api.SetDefaultRatelimitMode(csfloat.RatelimitModeFailFast)
+	_, err := api.Me()
+	ass.NoError(err)
+
+	_, err = api.Me()
+	var ratelimitErr *csfloat.RatelimitError
+	if ass.ErrorAs(err, &ratelimitErr) {
+		ass.Equal(csfloat.RatelimitKeyGetMe, ratelimitErr.Bucket)
+		ass.Equal(reset.Unix(), ratelimitErr.RetryAt.Unix())
+	}
+
+	api.SetRatelimitMode(csfloat.RatelimitKeyGetMe, csfloat.RatelimitModeWait)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = api.MeContext(ctx)
+	ass.ErrorIs(err, context.DeadlineExceeded)
+
+	api.SetRatelimitMode(csfloat.RatelimitKeyGetMe, csfloat.RatelimitModeOff)
+	_, err = api.Me()
+	ass.NoError(err)
+
+	ass.Equal(int64(2), requests.Load())
+}
+
+func Test_IPRatelimitLearned(t *testing.T) {
+	ass := assert.New(t)
+
+	var requests atomic.Int64
+	reset := time.Now().Add(time.Hour).Unix()
+	api := newTestAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Ratelimit-Limit", "1000")
+		w.Header().Set("X-Ratelimit-Remaining", "900")
+		w.Header().Set("X-Ratelimit-Reset", strconv.FormatInt(reset, 10))
+		if requests.Add(1) > 3 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"code":0,"message":"too many requests"}`))
+			return
+		}
+		w.Write([]byte(`{"user":{}}`))
+	}))
+
+	for range 3 {
+		_, err := api.Me()
+		ass.NoError(err)
+	}
+	ipRatelimits := api.IPRatelimits(csfloat.IPv4)
+	ass.Equal(uint(3), ipRatelimits.Used)
+	_, known := ipRatelimits.Remaining()
+	ass.False(known)
+	ass.False(api.IsGloballyRatelimited())
+
+	_, err := api.Me()
+	ass.Error(err)
+
+	ipRatelimits = api.Snapshot().IP[csfloat.IPv4]
+	ass.Equal(uint(3), ipRatelimits.Limit)
+	ass.True(ipRatelimits.Learned)
+	ass.Equal(uint(4), ipRatelimits.Used)
+	ass.True(api.IsGloballyRatelimited())
+	ass.Zero(api.IPRatelimits(csfloat.IPv6).Used)
+
+	api.SetIPRatelimit(csfloat.IPv4, 100)
+	remaining, known := api.IPRatelimits(csfloat.IPv4).Remaining()
+	ass.True(known)
+	ass.Equal(uint(96), remaining)
+	ass.False(api.IsGloballyRatelimited())
+}
@@ -0,0 +1,247 @@
+package csfloat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Outcome is the actual result of a mutating request that failed in a way
+// where it is unclear whether it was applied.
+type Outcome uint8
+
+const (
+	// OutcomeUnknown means the outcome couldn't be determined, or the request
+	// was only partially applied. Do not blindly retry the request.
+	OutcomeUnknown Outcome = iota
+	// OutcomeConfirmed means the request was applied, despite the error.
+	OutcomeConfirmed
+	// OutcomeNotApplied means the request wasn't applied and can be sent
+	// again.
+	OutcomeNotApplied
+)
+
+func (outcome Outcome) String() string {
+	switch outcome {
+	case OutcomeConfirmed:
+		return "confirmed"
+	case OutcomeNotApplied:
+		return "not applied"
+	default:
+		return "unknown"
+	}
+}
+
+// AmbiguousError is returned by Buy, List, BulkList and BulkUnlist if the
+// request failed without a definitive answer from CSFloat, for example due to
+// a timeout. In these cases, the request might still have been applied. The
+// actual outcome is determined by checking the trades, listings or the stall
+// afterwards.
+type AmbiguousError struct {
+	Outcome Outcome
+	// Err is the error of the original request.
+	Err error
+	// ReconcileErr is set if determining the outcome failed. The Outcome is
+	// OutcomeUnknown in that case.
+	ReconcileErr error
+}
+
+func (err *AmbiguousError) Error() string {
+	if err.ReconcileErr != nil {
+		return fmt.Sprintf("request outcome %s (%v): %v", err.Outcome, err.ReconcileErr, err.Err)
+	}
+	return fmt.Sprintf("request outcome %s: %v", err.Outcome, err.Err)
+}
+
+func (err *AmbiguousError) Unwrap() error {
+	return err.Err
+}
+
+// ReconcilePolicy configures how the outcome of ambiguous requests is
+// determined.
+type ReconcilePolicy struct {
+	// Delay is waited before checking the outcome. CSFloat sometimes keeps
+	// processing requests in the background after the connection is gone.
+	Delay time.Duration
+	// Timeout limits the time spent on all requests required for checking
+	// the outcome. It is independent of the context of the original request,
+	// as that has usually expired already.
+	Timeout time.Duration
+}
+
+// DefaultReconcilePolicy returns the policy used unless SetReconcilePolicy
+// is called.
+func DefaultReconcilePolicy() ReconcilePolicy {
+	return ReconcilePolicy{
+		Delay:   3 * time.Second,
+		Timeout: 30 * time.Second,
+	}
+}
+
+// SetReconcilePolicy sets the policy used for all subsequent requests.
+func (api *API) SetReconcilePolicy(policy ReconcilePolicy) {
+	api.reconcilePolicy.Store(&policy)
+}
+
+// isAmbiguous reports whether the request might have been applied, despite
+// failing. This is the case if the request was sent, but no proper response
+// was received.
+func isAmbiguous(err error, attempts []Attempt) bool {
+	if err == nil || len(attempts) == 0 {
+		return false
+	}
+
+	attempt := attempts[len(attempts)-1]
+	if attempt.StatusCode == 0 {
+		// Without a response, the request is only ambiguous if it was
+		// actually written. Otherwise, for example if the connection
+		// couldn't be established or the context was cancelled early,
+		// CSFloat can't have processed it.
+		return attempt.WroteRequest
+	}
+	return isTransientStatus(attempt.StatusCode)
+}
+
+// reconcile determines the outcome of an ambiguous request using check. The
+// given context is only used for its values, not its cancellation.
+func (api *API) reconcile(
+	ctx context.Context,
+	requestErr error,
+	check func(ctx context.Context) (Outcome, error),
+) *AmbiguousError {
+	policy := DefaultReconcilePolicy()
+	if configured := api.reconcilePolicy.Load(); configured != nil {
+		policy = *configured
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), policy.Timeout)
+	defer cancel()
+
+	ambiguousErr := &AmbiguousError{Err: requestErr}
+	if err := sleep(ctx, policy.Delay); err != nil {
+		ambiguousErr.ReconcileErr = err
+		return ambiguousErr
+	}
+
+	ambiguousErr.Outcome, ambiguousErr.ReconcileErr = check(ctx)
+	if ambiguousErr.ReconcileErr != nil {
+		ambiguousErr.Outcome = OutcomeUnknown
+	}
+	return ambiguousErr
+}
+
+// outcomeOf maps the amount of applied parts of a request to an outcome.
+func outcomeOf(applied, total int) Outcome {
+	switch applied {
+	case total:
+		return OutcomeConfirmed
+	case 0:
+		return OutcomeNotApplied
+	default:
+		return OutcomeUnknown
+	}
+}
+
+// tradeTimeSlack is subtracted from the start of a buy request, when looking
+// for the resulting trades, to allow for clock differences between us and
+// CSFloat.
+const tradeTimeSlack = 5 * time.Minute
+
+// reconcileBuy checks whether the contracts show up in our trades created
+// after since. Trades are returned newest first, so older trades aren't
+// fetched. Contracts that aren't in our trades are only considered not
+// bought, if they are still listed, as the purchase might still be processing
+// otherwise.
+func (api *API) reconcileBuy(ctx context.Context, payload BuyRequestPayload, since time.Time) (Outcome, error) {
+	traded := make(map[string]bool, len(payload.ContractIds))
+	for _, contractId := range payload.ContractIds {
+		traded[contractId] = false
+	}
+	var found int
+	for trade, err := range api.AllTrades(ctx, TradesRequest{}) {
+		if err != nil {
+			return OutcomeUnknown, fmt.Errorf("error getting trades: %w", err)
+		}
+		if trade.CreatedAt.Before(since) {
+			break
+		}
+		if isTraded, ok := traded[trade.Contract.ID]; ok && !isTraded {
+			traded[trade.Contract.ID] = true
+			if found++; found == len(traded) {
+				break
+			}
+		}
+	}
+
+	var bought int
+	for _, contractId := range payload.ContractIds {
+		if traded[contractId] {
+			bought++
+			continue
+		}
+
+		listing, err := api.ListingContext(ctx, contractId)
+		if err != nil {
+			return OutcomeUnknown, fmt.Errorf("error getting listing: %w", err)
+		}
+		if listing.Item.State != ListingStateListed {
+			return OutcomeUnknown, nil
+		}
+	}
+
+	return outcomeOf(bought, len(payload.ContractIds)), nil
+}
+
+// stallListings returns our own listings, mapped by asset id.
+func (api *API) stallListings(ctx context.Context) (map[string]ActiveListing, error) {
+	me, err := api.MeContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting own steam id: %w", err)
+	}
+
+	listings := make(map[string]ActiveListing)
+	for listing, err := range api.AllStall(ctx, me.User.SteamId, StallRequest{Limit: 50}) {
+		if err != nil {
+			return nil, fmt.Errorf("error getting stall: %w", err)
+		}
+		listings[listing.Item.ID] = listing
+	}
+	return listings, nil
+}
+
+// reconcileList checks which of the given assets are listed in our stall and
+// returns the respective listings.
+func (api *API) reconcileList(ctx context.Context, items []ListRequest) ([]ActiveListing, Outcome, error) {
+	stall, err := api.stallListings(ctx)
+	if err != nil {
+		return nil, OutcomeUnknown, err
+	}
+
+	var listed []ActiveListing
+	for _, item := range items {
+		if listing, ok := stall[item.AssetId]; ok {
+			listed = append(listed, listing)
+		}
+	}
+	return listed, outcomeOf(len(listed), len(items)), nil
+}
+
+// reconcileUnlist checks whether the given listings are gone.
+func (api *API) reconcileUnlist(ctx context.Context, listingIds []string) (Outcome, error) {
+	var unlisted int
+	for _, listingId := range listingIds {
+		listing, err := api.ListingContext(ctx, listingId)
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				unlisted++
+				continue
+			}
+			return OutcomeUnknown, fmt.Errorf("error getting listing: %w", err)
+		}
+		if listing.Item.State != ListingStateListed {
+			unlisted++
+		}
+	}
+	return outcomeOf(unlisted, len(listingIds)), nil
+}
@@ -0,0 +1,172 @@
+package csfloat_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	csfloat "github.com/Bios-Marcel/csfloat_go"
+	"github.com/stretchr/testify/assert"
+)
+
+func newReconcileTestAPI(t *testing.T, mux *http.ServeMux) *csfloat.API {
+	api := newTestAPI(t, mux)
+	api.SetReconcilePolicy(csfloat.ReconcilePolicy{Timeout: time.Second})
+	return api
+}
+
+func Test_BuyReconciled(t *testing.T) {
+	ass := assert.New(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/listings/buy", func(w http.ResponseWriter, r *http.Request) {
+		dropConnection(w)
+	})
+	mux.HandleFunc("GET /api/v1/me/trades", func(w http.ResponseWriter, r *http.Request) {
+		createdAt := time.Now().Format(time.RFC3339)
+		writeJSON(w, `{"trades":[{"id":"t1","contract":{"id":"1"},"state":"queued","created_at":"`+createdAt+`"}],"count":1}`)
+	})
+	mux.HandleFunc("GET /api/v1/listings/{id}", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, `{"id":"`+r.PathValue("id")+`","state":"listed"}`)
+	})
+	api := newReconcileTestAPI(t, mux)
+
+	_, err := api.Buy(csfloat.BuyRequestPayload{ContractIds: []string{"1"}, TotalPrice: 1})
+	var ambiguousErr *csfloat.AmbiguousError
+	if ass.ErrorAs(err, &ambiguousErr) {
+		ass.Equal(csfloat.OutcomeConfirmed, ambiguousErr.Outcome)
+		ass.NoError(ambiguousErr.ReconcileErr)
+	}
+
+	_, err = api.Buy(csfloat.BuyRequestPayload{ContractIds: []string{"2"}, TotalPrice: 1})
+	if ass.ErrorAs(err, &ambiguousErr) {
+		ass.Equal(csfloat.OutcomeNotApplied, ambiguousErr.Outcome)
+	}
+
+	_, err = api.Buy(csfloat.BuyRequestPayload{ContractIds: []string{"1", "2"}, TotalPrice: 2})
+	if ass.ErrorAs(err, &ambiguousErr) {
+		ass.Equal(csfloat.OutcomeUnknown, ambiguousErr.Outcome)
+	}
+}
+
+func Test_ListReconciled(t *testing.T) {
+	ass := assert.New(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/listings", func(w http.ResponseWriter, r *http.Request) {
+		dropConnection(w)
+	})
+	mux.HandleFunc("GET /api/v1/me", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, `{"user":{"steam_id":"76561198000000000"}}`)
+	})
+	mux.HandleFunc("GET /api/v1/users/76561198000000000/stall", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, `{"data":[{"id":"l1","item":{"asset_id":"a1"},"state":"listed"}],"total_count":1}`)
+	})
+	api := newReconcileTestAPI(t, mux)
+
+	response, err := api.List(csfloat.ListRequest{AssetId: "a1"})
+	var ambiguousErr *csfloat.AmbiguousError
+	if ass.ErrorAs(err, &ambiguousErr) {
+		ass.Equal(csfloat.OutcomeConfirmed, ambiguousErr.Outcome)
+		ass.Equal("l1", response.Item.ID)
+	}
+
+	_, err = api.List(csfloat.ListRequest{AssetId: "a2"})
+	if ass.ErrorAs(err, &ambiguousErr) {
+		ass.Equal(csfloat.OutcomeNotApplied, ambiguousErr.Outcome)
+	}
+}
+
+func Test_BulkUnlistReconciled(t *testing.T) {
+	ass := assert.New(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("PATCH /api/v1/listings/bulk-delist", func(w http.ResponseWriter, r *http.Request) {
+		dropConnection(w)
+	})
+	mux.HandleFunc("GET /api/v1/listings/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("id") == "gone" {
+			w.Header().Set("X-Ratelimit-Limit", "100")
+			w.Header().Set("X-Ratelimit-Remaining", "99")
+			w.Header().Set("X-Ratelimit-Reset", "0")
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code":3,"message":"not found"}`))
+			return
+		}
+		writeJSON(w, `{"id":"`+r.PathValue("id")+`","state":"delisted"}`)
+	})
+	api := newReconcileTestAPI(t, mux)
+
+	_, err := api.BulkUnlist("gone", "delisted")
+	var ambiguousErr *csfloat.AmbiguousError
+	if ass.ErrorAs(err, &ambiguousErr) {
+		ass.Equal(csfloat.OutcomeConfirmed, ambiguousErr.Outcome)
+	}
+}
+
+func Test_BuyReconciledAcrossPages(t *testing.T) {
+	ass := assert.New(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/listings/buy", func(w http.ResponseWriter, r *http.Request) {
+		dropConnection(w)
+	})
+	mux.HandleFunc("GET /api/v1/listings/{id}", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, `{"id":"`+r.PathValue("id")+`","state":"listed"}`)
+	})
+	var pages []string
+	mux.HandleFunc("GET /api/v1/me/trades", func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		pages = append(pages, page)
+		// Starting with the third page, all trades predate the purchase.
+		createdAt := time.Now()
+		if page >= "2" {
+			createdAt = createdAt.Add(-24 * time.Hour)
+		}
+		trades := make([]string, 0, 100)
+		for i := range 100 {
+			trades = append(trades, `{"id":"t`+page+`-`+strconv.Itoa(i)+`","contract":{"id":"c`+page+`-`+strconv.Itoa(i)+`"},"created_at":"`+createdAt.Format(time.RFC3339)+`"}`)
+		}
+		w.Header().Set("X-Ratelimit-Limit", "100")
+		w.Header().Set("X-Ratelimit-Remaining", "99")
+		w.Header().Set("X-Ratelimit-Reset", "0")
+		w.Write([]byte(`{"trades":[` + strings.Join(trades, ",") + `],"count":500}`))
+	})
+	api := newReconcileTestAPI(t, mux)
+
+	_, err := api.Buy(csfloat.BuyRequestPayload{ContractIds: []string{"c1-5", "c0-99"}, TotalPrice: 2})
+	var ambiguousErr *csfloat.AmbiguousError
+	if ass.ErrorAs(err, &ambiguousErr) {
+		ass.Equal(csfloat.OutcomeConfirmed, ambiguousErr.Outcome)
+		ass.NoError(ambiguousErr.ReconcileErr)
+	}
+	// Paging stops as soon as all contracts have been found.
+	ass.Equal([]string{"0", "1"}, pages)
+
+	// Trades older than the purchase can't contain it, so they aren't
+	// fetched, even if the contract isn't found.
+	pages = nil
+	_, err = api.Buy(csfloat.BuyRequestPayload{ContractIds: []string{"c3-5"}, TotalPrice: 1})
+	if ass.ErrorAs(err, &ambiguousErr) {
+		ass.Equal(csfloat.OutcomeNotApplied, ambiguousErr.Outcome)
+		ass.NoError(ambiguousErr.ReconcileErr)
+	}
+	ass.Equal([]string{"0", "1", "2"}, pages)
+}
+
+func Test_BuyNotSent(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+	api := csfloat.New("", csfloat.WithBaseURL(server.URL+"/api/v1"))
+	api.SetReconcilePolicy(csfloat.ReconcilePolicy{Delay: time.Hour, Timeout: time.Hour})
+
+	response, err := api.Buy(csfloat.BuyRequestPayload{ContractIds: []string{"1"}, TotalPrice: 1})
+	assert.Error(t, err)
+	assert.NotErrorAs(t, err, new(*csfloat.AmbiguousError))
+	if assert.Len(t, response.Attempts, 1) {
+		assert.False(t, response.Attempts[0].WroteRequest)
+	}
+}
@@ -0,0 +1,202 @@
+package csfloat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// RepriceStrategy computes a new price for one of our listings. It receives
+// the price proposed so far, which is the listing's current price unless
+// strategies are chained via RepriceChain, and returns its own proposal.
+// Returning the proposed price unchanged keeps it.
+type RepriceStrategy interface {
+	Reprice(ctx context.Context, api *API, listing *ActiveListing, proposed int) (int, error)
+}
+
+// RepriceFunc adapts a function to a RepriceStrategy.
+type RepriceFunc func(ctx context.Context, api *API, listing *ActiveListing, proposed int) (int, error)
+
+func (fn RepriceFunc) Reprice(ctx context.Context, api *API, listing *ActiveListing, proposed int) (int, error) {
+	return fn(ctx, api, listing, proposed)
+}
+
+// RepriceChain applies the strategies in order, passing each proposal on to
+// the next strategy. Put limits, such as CostBasisFloor, last.
+func RepriceChain(strategies ...RepriceStrategy) RepriceStrategy {
+	return RepriceFunc(func(ctx context.Context, api *API, listing *ActiveListing, proposed int) (int, error) {
+		for _, strategy := range strategies {
+			var err error
+			if proposed, err = strategy.Reprice(ctx, api, listing, proposed); err != nil {
+				return 0, err
+			}
+		}
+		return proposed, nil
+	})
+}
+
+// UndercutSimilar prices the listing By cents below the cheapest buy now
+// listing returned by Similar, ignoring our own listings. If there are no
+// such listings, or undercutting would leave no positive price, the proposal
+// is kept. Between listings, the SuggestedWait of the get_similar bucket is
+// awaited.
+type UndercutSimilar struct {
+	By int
+}
+
+func (undercut UndercutSimilar) Reprice(ctx context.Context, api *API, listing *ActiveListing, proposed int) (int, error) {
+	if err := api.awaitSuggestedWait(ctx, RatelimitKeyGetSimilar); err != nil {
+		return 0, err
+	}
+	similar, err := api.SimilarContext(ctx, listing.ID)
+	if err != nil {
+		return 0, fmt.Errorf("error getting similar listings: %w", err)
+	}
+
+	cheapest := math.MaxInt
+	for _, other := range similar.Data {
+		if other.Type != BuyNow || other.ID == listing.ID ||
+			(listing.Seller.SteamID != "" && other.Seller.SteamID == listing.Seller.SteamID) {
+			continue
+		}
+		cheapest = min(cheapest, other.Price)
+	}
+	if cheapest == math.MaxInt || cheapest <= undercut.By {
+		return proposed, nil
+	}
+	return cheapest - undercut.By, nil
+}
+
+// ReferenceRatio prices the listing at Ratio times Reference.PredictedPrice,
+// for example 1.05 for 5% above the reference. Listings without reference
+// keep the proposal.
+type ReferenceRatio struct {
+	Ratio float64
+}
+
+func (ratio ReferenceRatio) Reprice(_ context.Context, _ *API, listing *ActiveListing, proposed int) (int, error) {
+	if listing.Reference.PredictedPrice == 0 {
+		return proposed, nil
+	}
+	return int(math.Round(float64(listing.Reference.PredictedPrice) * ratio.Ratio)), nil
+}
+
+// CostBasisFloor never lets the price drop below what we paid for the item
+// plus Margin cents. CostBasis returns the amount paid, or false if unknown,
+// in which case the proposal is kept.
+type CostBasisFloor struct {
+	CostBasis func(listing *ActiveListing) (int, bool)
+	Margin    int
+}
+
+func (floor CostBasisFloor) Reprice(_ context.Context, _ *API, listing *ActiveListing, proposed int) (int, error) {
+	costBasis, ok := floor.CostBasis(listing)
+	if !ok {
+		return proposed, nil
+	}
+	return max(proposed, costBasis+floor.Margin), nil
+}
+
+// TimeDecay prices the listing at a base price, lowered by PerDay, for
+// example 0.01 for 1%, for every day since the listing was created,
+// compounding, but by no more than MaxDecay in total. The base is
+// Reference.PredictedPrice, unless Base is set. As the decay always starts
+// from the base rather than the current price, repeated runs don't compound.
+// Listings without base price keep the proposal.
+type TimeDecay struct {
+	PerDay   float64
+	MaxDecay float64
+	// Base returns the price to decay from, or false if unknown. It could
+	// for example return the initial price of the listing.
+	Base func(listing *ActiveListing) (int, bool)
+}
+
+func (decay TimeDecay) Reprice(_ context.Context, _ *API, listing *ActiveListing, proposed int) (int, error) {
+	base, ok := listing.Reference.PredictedPrice, listing.Reference.PredictedPrice > 0
+	if decay.Base != nil {
+		base, ok = decay.Base(listing)
+	}
+	if !ok {
+		return proposed, nil
+	}
+
+	days := max(time.Since(listing.CreatedAt).Hours()/24, 0)
+	factor := math.Max(math.Pow(1-decay.PerDay, days), 1-decay.MaxDecay)
+	return int(math.Round(float64(base) * factor)), nil
+}
+
+// ErrInvalidPrice is returned by PlanReprice, if a strategy proposes a price
+// of zero or less.
+var ErrInvalidPrice = errors.New("invalid price")
+
+// RepriceChange is a single price change of a RepricePlan.
+type RepriceChange struct {
+	// Listing is the listing before the change.
+	Listing  ActiveListing
+	NewPrice int
+}
+
+// String describes the change in a single line.
+func (change RepriceChange) String() string {
+	return fmt.Sprintf("~ %s (%s): price %d -> %d",
+		change.Listing.Item.MarketHashName, change.Listing.ID, change.Listing.Price, change.NewPrice)
+}
+
+// RepricePlan holds the new prices computed by PlanReprice. Its String output
+// doubles as a dry run, ApplyReprice carries it out.
+type RepricePlan struct {
+	Changes []RepriceChange
+	// Unchanged is the amount of listings that keep their price.
+	Unchanged int
+}
+
+// String lists the price changes and how many listings keep their price.
+func (plan *RepricePlan) String() string {
+	return formatPlan(plan.Changes, fmt.Sprintf("%d to reprice, %d unchanged", len(plan.Changes), plan.Unchanged))
+}
+
+// PlanReprice walks all buy now listings of our stall and computes their new
+// prices using the strategy, without applying them.
+func (api *API) PlanReprice(ctx context.Context, strategy RepriceStrategy) (*RepricePlan, error) {
+	me, err := api.MeContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting own steam id: %w", err)
+	}
+
+	plan := &RepricePlan{}
+	for listing, err := range api.AllStall(ctx, me.User.SteamId, StallRequest{Limit: 50, Type: BuyNow}) {
+		if err != nil {
+			return nil, fmt.Errorf("error getting stall: %w", err)
+		}
+
+		price, err := strategy.Reprice(ctx, api, &listing, listing.Price)
+		if err != nil {
+			return nil, fmt.Errorf("error repricing listing %s: %w", listing.ID, err)
+		}
+		if price <= 0 {
+			return nil, fmt.Errorf("%w: %d for listing %s", ErrInvalidPrice, price, listing.ID)
+		}
+		if price == listing.Price {
+			plan.Unchanged++
+			continue
+		}
+		plan.Changes = append(plan.Changes, RepriceChange{Listing: listing, NewPrice: price})
+	}
+	return plan, nil
+}
+
+// ApplyReprice sets the new prices via UpdatePrice, pacing the requests by
+// the SuggestedWait of the update_listing bucket. If a request fails, the
+// remaining changes are skipped and the ones already made are returned with
+// the error.
+func (api *API) ApplyReprice(ctx context.Context, plan *RepricePlan) ([]RepriceChange, error) {
+	return applyPlan(plan.Changes, func(change RepriceChange) error {
+		if err := api.awaitSuggestedWait(ctx, RatelimitKeyUpdateListing); err != nil {
+			return err
+		}
+		_, err := api.UpdatePriceContext(ctx, change.Listing.ID, uint(change.NewPrice))
+		return err
+	})
+}
@@ -0,0 +1,131 @@
+package csfloat_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	csfloat "github.com/Bios-Marcel/csfloat_go"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Reprice(t *testing.T) {
+	ass := assert.New(t)
+	server, api := newFakeServer(t)
+	me := csfloat.Seller{SteamID: server.Me().SteamId}
+	createdAt := time.Now().Add(-time.Hour)
+	for _, listing := range []csfloat.ActiveListing{
+		{ID: "1", Price: 1000, Seller: me, Item: csfloat.Item{ID: "a", MarketHashName: "AK-47 | Redline (Field-Tested)"}},
+		{ID: "2", Price: 5000, Seller: me, Item: csfloat.Item{ID: "b", MarketHashName: "AWP | Asiimov (Field-Tested)"}},
+		{ID: "3", Price: 300, Seller: me, Item: csfloat.Item{ID: "c", MarketHashName: "Glock-18 | Fade (Factory New)"}},
+		{ID: "4", Price: 900, Seller: me, Item: csfloat.Item{ID: "d", MarketHashName: "M4A4 | Howl (Minimal Wear)"}, Type: csfloat.Auction},
+		{ID: "5", Price: 950, Item: csfloat.Item{MarketHashName: "AK-47 | Redline (Field-Tested)"}},
+		{ID: "6", Price: 200, Item: csfloat.Item{MarketHashName: "Glock-18 | Fade (Factory New)"}},
+	} {
+		listing.CreatedAt = createdAt
+		server.AddListing(listing)
+	}
+
+	costBasis := map[string]int{"c": 240}
+	strategy := csfloat.RepriceChain(
+		csfloat.UndercutSimilar{By: 1},
+		csfloat.CostBasisFloor{
+			CostBasis: func(listing *csfloat.ActiveListing) (int, bool) {
+				cost, ok := costBasis[listing.Item.ID]
+				return cost, ok
+			},
+			Margin: 10,
+		},
+	)
+	plan, err := api.PlanReprice(context.Background(), strategy)
+	if !ass.NoError(err) {
+		return
+	}
+	ass.Equal(`~ AK-47 | Redline (Field-Tested) (1): price 1000 -> 949
+~ Glock-18 | Fade (Factory New) (3): price 300 -> 250
+
+Plan: 2 to reprice, 1 unchanged.
+`, plan.String())
+
+	applied, err := api.ApplyReprice(context.Background(), plan)
+	ass.NoError(err)
+	ass.Len(applied, 2)
+	listing, _ := server.Listing("1")
+	ass.Equal(949, listing.Price)
+	listing, _ = server.Listing("3")
+	ass.Equal(250, listing.Price)
+
+	plan, err = api.PlanReprice(context.Background(), strategy)
+	if ass.NoError(err) {
+		ass.Equal("No changes.\n", plan.String())
+	}
+}
+
+func Test_UndercutSimilarKeepsPositivePrice(t *testing.T) {
+	ass := assert.New(t)
+	server, api := newFakeServer(t)
+	me := csfloat.Seller{SteamID: server.Me().SteamId}
+	for _, listing := range []csfloat.ActiveListing{
+		{ID: "1", Price: 1000, Seller: me, Item: csfloat.Item{ID: "a", MarketHashName: "AK-47 | Redline (Field-Tested)"}},
+		{ID: "2", Price: 5, Seller: me, Item: csfloat.Item{ID: "b", MarketHashName: "P250 | Sand Dune (Field-Tested)"}},
+		{ID: "3", Price: 950, Item: csfloat.Item{MarketHashName: "AK-47 | Redline (Field-Tested)"}},
+		{ID: "4", Price: 3, Item: csfloat.Item{MarketHashName: "P250 | Sand Dune (Field-Tested)"}},
+	} {
+		server.AddListing(listing)
+	}
+
+	// Undercutting the cheap listing by 5 would price it at -2, which must
+	// not stop the other listing from being repriced.
+	plan, err := api.PlanReprice(context.Background(), csfloat.UndercutSimilar{By: 5})
+	if ass.NoError(err) {
+		ass.Equal(`~ AK-47 | Redline (Field-Tested) (1): price 1000 -> 945
+
+Plan: 1 to reprice, 1 unchanged.
+`, plan.String())
+	}
+}
+
+func Test_RepriceStrategies(t *testing.T) {
+	ass := assert.New(t)
+	ctx := context.Background()
+	listing := &csfloat.ActiveListing{
+		Price:     1000,
+		CreatedAt: time.Now().Add(-24 * time.Hour),
+		Reference: csfloat.ItemReference{PredictedPrice: 1000},
+	}
+
+	price, err := csfloat.ReferenceRatio{Ratio: 1.05}.Reprice(ctx, nil, listing, listing.Price)
+	ass.NoError(err)
+	ass.Equal(1050, price)
+
+	decay := csfloat.TimeDecay{PerDay: 0.1, MaxDecay: 0.15}
+	price, err = decay.Reprice(ctx, nil, listing, 1000)
+	ass.NoError(err)
+	ass.Equal(900, price)
+	// Decaying from the reference, repeated runs don't compound.
+	price, err = decay.Reprice(ctx, nil, listing, price)
+	ass.NoError(err)
+	ass.Equal(900, price)
+
+	listing.CreatedAt = time.Now().Add(-72 * time.Hour)
+	price, err = decay.Reprice(ctx, nil, listing, 1000)
+	ass.NoError(err)
+	ass.Equal(850, price)
+
+	decay.Base = func(*csfloat.ActiveListing) (int, bool) { return 2000, true }
+	price, err = decay.Reprice(ctx, nil, listing, 1000)
+	ass.NoError(err)
+	ass.Equal(1700, price)
+
+	price, err = csfloat.TimeDecay{PerDay: 0.1}.Reprice(ctx, nil, &csfloat.ActiveListing{Price: 700}, 700)
+	ass.NoError(err)
+	ass.Equal(700, price)
+
+	_, err = csfloat.RepriceChain(
+		csfloat.ReferenceRatio{Ratio: 1},
+		csfloat.RepriceFunc(func(context.Context, *csfloat.API, *csfloat.ActiveListing, int) (int, error) {
+			return 0, assert.AnError
+		}),
+	).Reprice(ctx, nil, listing, listing.Price)
+	ass.ErrorIs(err, assert.AnError)
+}
@@ -0,0 +1,131 @@
+package csfloat
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"slices"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy configures retries for requests that failed due to transient
+// errors, such as timeouts, 502 / 503 responses or HTML error pages. The zero
+// value disables retries.
+//
+// Only GET requests are retried by default, as mutating requests might have
+// been applied despite failing. For example, retrying a Buy could purchase an
+// item twice.
+type RetryPolicy struct {
+	// MaxAttempts is the total amount of attempts, including the first one.
+	// Values below 2 disable retries.
+	MaxAttempts uint
+	// BaseDelay is the delay before the first retry. It doubles with each
+	// further attempt.
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between two attempts. 0 means no cap. Waiting
+	// for a bucket reset after a 429 isn't capped.
+	MaxDelay time.Duration
+	// Jitter is the fraction of each delay that is randomized, between 0 and
+	// 1. This prevents multiple clients from retrying in lockstep.
+	Jitter float64
+	// MutatingBuckets opts the given buckets into retries, even though their
+	// requests aren't GET requests. Only use this for endpoints where
+	// applying a request twice is harmless.
+	MutatingBuckets []RatelimitBucketKey
+}
+
+// DefaultRetryPolicy returns a sensible policy for retrying GET requests.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   500 * time.Millisecond,
+		MaxDelay:    10 * time.Second,
+		Jitter:      0.2,
+	}
+}
+
+// SetRetryPolicy sets the policy used for all subsequent requests.
+func (api *API) SetRetryPolicy(policy RetryPolicy) {
+	api.retryPolicy.Store(&policy)
+}
+
+func (policy *RetryPolicy) allows(method string, key RatelimitBucketKey) bool {
+	if policy.MaxAttempts < 2 {
+		return false
+	}
+	return method == http.MethodGet || slices.Contains(policy.MutatingBuckets, key)
+}
+
+// delay returns the backoff after the given attempt, starting at 1.
+func (policy *RetryPolicy) delay(attempt uint) time.Duration {
+	delay := policy.BaseDelay << (attempt - 1)
+	// The shift overflows for absurd attempt counts.
+	if delay < policy.BaseDelay || (policy.MaxDelay > 0 && delay > policy.MaxDelay) {
+		delay = policy.MaxDelay
+	}
+	if policy.Jitter > 0 {
+		jitter := time.Duration(float64(delay) * min(policy.Jitter, 1))
+		delay = delay - jitter + rand.N(2*jitter+1)
+	}
+	return delay
+}
+
+// Attempt describes a single try of sending a request.
+type Attempt struct {
+	// Number starts at 1.
+	Number    uint
+	StartedAt time.Time
+	Duration  time.Duration
+	// StatusCode is 0 if no response was received.
+	StatusCode int
+	// WroteRequest reports whether the request was completely written to
+	// the connection. If not, CSFloat can't have processed it.
+	WroteRequest bool
+	// Err is nil if the attempt succeeded.
+	Err error
+	// Backoff is the time waited after this attempt, before the next one.
+	Backoff time.Duration
+}
+
+// attemptOutcome carries the information required to decide whether an
+// attempt should be retried.
+type attemptOutcome struct {
+	statusCode   int
+	transient    bool
+	wroteRequest bool
+	// retryAt is the earliest time for the next attempt, for example the
+	// bucket reset after a 429.
+	retryAt time.Time
+}
+
+// isTransientNetworkError reports whether the error is likely to go away if
+// the request is simply sent again.
+func isTransientNetworkError(ctx context.Context, err error) bool {
+	// If we cancelled ourselves, the error is caused by us, not the network.
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// isTransientStatus reports whether the status code indicates a temporary
+// failure of CSFloat or anything in front of it.
+func isTransientStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
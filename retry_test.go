@@ -0,0 +1,96 @@
+package csfloat_test
+
+import (
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	csfloat "github.com/Bios-Marcel/csfloat_go"
+	"github.com/stretchr/testify/assert"
+)
+
+// flakyHandler fails the given amount of requests with an HTML 503, just like
+// the proxy in front of CSFloat does, before succeeding.
+func flakyHandler(failures int64, requests *atomic.Int64) http.Handler {
+	reset := time.Now().Add(time.Hour).Unix()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) <= failures {
+			w.Header().Set("Content-Type", "text/html")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte("<html>503 Service Temporarily Unavailable</html>"))
+			return
+		}
+		w.Header().Set("X-Ratelimit-Limit", "100")
+		w.Header().Set("X-Ratelimit-Remaining", "99")
+		w.Header().Set("X-Ratelimit-Reset", strconv.FormatInt(reset, 10))
+		w.Write([]byte(`{"user":{"steam_id":"1"}}`))
+	})
+}
+
+func Test_RetryPolicy(t *testing.T) {
+	ass := assert.New(t)
+
+	var requests atomic.Int64
+	api := newTestAPI(t, flakyHandler(2, &requests))
+	api.SetRetryPolicy(csfloat.RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Millisecond,
+		Jitter:      0.5,
+	})
+
+	response, err := api.Me()
+	if ass.NoError(err) {
+		ass.Equal("1", response.User.SteamId)
+		ass.Nil(response.Error)
+	}
+	if ass.Len(response.Attempts, 3) {
+		ass.Equal(http.StatusServiceUnavailable, response.Attempts[0].StatusCode)
+		ass.Error(response.Attempts[0].Err)
+		ass.Positive(response.Attempts[0].Backoff)
+		ass.Equal(http.StatusOK, response.Attempts[2].StatusCode)
+		ass.NoError(response.Attempts[2].Err)
+		ass.Equal(uint(3), response.Attempts[2].Number)
+	}
+}
+
+func Test_RetryPolicyExhausted(t *testing.T) {
+	var requests atomic.Int64
+	api := newTestAPI(t, flakyHandler(5, &requests))
+	api.SetRetryPolicy(csfloat.RetryPolicy{
+		MaxAttempts: 2,
+		BaseDelay:   time.Millisecond,
+	})
+
+	response, err := api.Me()
+	assert.Error(t, err)
+	assert.Len(t, response.Attempts, 2)
+	assert.Equal(t, int64(2), requests.Load())
+}
+
+func Test_RetryPolicyMutating(t *testing.T) {
+	ass := assert.New(t)
+
+	var requests atomic.Int64
+	api := newTestAPI(t, flakyHandler(1, &requests))
+	api.SetReconcilePolicy(csfloat.ReconcilePolicy{Timeout: time.Second})
+	api.SetRetryPolicy(csfloat.RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Millisecond,
+	})
+
+	response, err := api.Buy(csfloat.BuyRequestPayload{ContractIds: []string{"1"}, TotalPrice: 1})
+	ass.Error(err)
+	ass.Len(response.Attempts, 1)
+
+	api.SetRetryPolicy(csfloat.RetryPolicy{
+		MaxAttempts:     3,
+		BaseDelay:       time.Millisecond,
+		MutatingBuckets: []csfloat.RatelimitBucketKey{csfloat.RatelimitKeyBuy},
+	})
+	requests.Store(0)
+	response, err = api.Buy(csfloat.BuyRequestPayload{ContractIds: []string{"1"}, TotalPrice: 1})
+	ass.NoError(err)
+	ass.Len(response.Attempts, 2)
+}
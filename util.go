@@ -12,6 +12,7 @@ func ApplyFee(value int, fee float64) (valueWithoutFee int, appliedFee int) {
 }
 
 // FloatRange returns the float range for the given quality (fn, mw, ...).
+// It doesn't account for the float caps of skins, see Item.FloatRange.
 func FloatRange(f float64) (float64, float64) {
 	if f < 0.07 {
 		return 0.0, 0.07
@@ -0,0 +1,114 @@
+package csfloat
+
+import (
+	"math"
+
+	"github.com/Bios-Marcel/csfloat_go/catalog"
+)
+
+// wearBrackets are the float ranges of all wears. They are the same for all
+// skins, only the floats a skin can have differ.
+var wearBrackets = []struct {
+	wear     WearName
+	min, max float64
+}{
+	{FactoryNew, 0, 0.07},
+	{MinimalWear, 0.07, 0.15},
+	{FieldTested, 0.15, 0.38},
+	{WellWorn, 0.38, 0.45},
+	{BattleScarred, 0.45, 1},
+}
+
+// wearNameOf maps a float to its wear.
+func wearNameOf(float float64) WearName {
+	for _, bracket := range wearBrackets[:len(wearBrackets)-1] {
+		if float < bracket.max {
+			return bracket.wear
+		}
+	}
+	return BattleScarred
+}
+
+// WearName returns the wear of the item's float. It is empty for items without
+// float, such as stickers.
+func (item *Item) WearName() WearName {
+	if item.Float <= 0 {
+		return ""
+	}
+	return wearNameOf(item.Float)
+}
+
+// FloatCap returns the minimum and maximum float of the item's skin, taken from
+// the catalog. If the skin isn't in the catalog, 0 and 1 are returned along
+// with false.
+func (item *Item) FloatCap() (float64, float64, bool) {
+	paint, ok := catalog.Default().Paint(item.PaintIndex)
+	if !ok || paint.MaxFloat <= paint.MinFloat {
+		return 0, 1, false
+	}
+	return paint.MinFloat, paint.MaxFloat, true
+}
+
+// FloatRange is like the package level FloatRange, but limited to the floats
+// the item's skin can have. For example, a Field-Tested AK-47 Redline can't go
+// below 0.15, but a Minimal Wear one can't go below 0.1.
+func (item *Item) FloatRange() (float64, float64) {
+	wearMin, wearMax := FloatRange(item.Float)
+	capMin, capMax, _ := item.FloatCap()
+	return max(wearMin, capMin), min(wearMax, capMax)
+}
+
+// NormalizedFloat maps the float into the skin's float cap, so 0 is the lowest
+// and 1 the highest float the skin can have. It returns false if the cap is
+// unknown.
+func (item *Item) NormalizedFloat() (float64, bool) {
+	capMin, capMax, ok := item.FloatCap()
+	if !ok {
+		return 0, false
+	}
+	return clamp01((item.Float - capMin) / (capMax - capMin)), true
+}
+
+// NearestWearBoundary returns the wear boundary closest to the item's float and
+// the absolute distance to it. Only boundaries within the skin's float cap are
+// considered. If there is none, because the cap lies within a single wear, or
+// the cap is unknown, false is returned.
+func (item *Item) NearestWearBoundary() (boundary float64, distance float64, ok bool) {
+	capMin, capMax, known := item.FloatCap()
+	if !known {
+		return 0, 0, false
+	}
+	distance = math.Inf(1)
+	for _, bracket := range wearBrackets[1:] {
+		if bracket.min <= capMin || bracket.min >= capMax {
+			continue
+		}
+		if current := math.Abs(item.Float - bracket.min); current < distance {
+			boundary, distance, ok = bracket.min, current, true
+		}
+	}
+	if !ok {
+		return 0, 0, false
+	}
+	return boundary, distance, true
+}
+
+// FloatRankInWear returns the position of the float within the floats its
+// wear can have for the item's skin, from 0 for the lowest to 1 for the highest
+// possible float. For example, a Field-Tested item with a FloatRankInWear of
+// 0.01 is in the lowest percent of all Field-Tested floats of that skin. It
+// returns false if the skin's float cap is unknown.
+func (item *Item) FloatRankInWear() (float64, bool) {
+	if _, _, ok := item.FloatCap(); !ok {
+		return 0, false
+	}
+	low, high := item.FloatRange()
+	if high <= low {
+		return 0, true
+	}
+	return clamp01((item.Float - low) / (high - low)), true
+}
+
+func clamp01(value float64) float64 {
+	return min(max(value, 0), 1)
+}
@@ -0,0 +1,106 @@
+package csfloat_test
+
+import (
+	"testing"
+
+	csfloat "github.com/Bios-Marcel/csfloat_go"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ItemWear(t *testing.T) {
+	type testCase struct {
+		name       string
+		item       csfloat.Item
+		wear       csfloat.WearName
+		normalized float64
+		rank       float64
+		boundary   float64
+		distance   float64
+	}
+
+	testCases := []testCase{
+		{
+			name:       "redline lowest minimal wear",
+			item:       csfloat.Item{PaintIndex: 282, Float: 0.1},
+			wear:       csfloat.MinimalWear,
+			normalized: 0,
+			rank:       0,
+			boundary:   0.15,
+			distance:   0.05,
+		},
+		{
+			name:       "redline field-tested",
+			item:       csfloat.Item{PaintIndex: 282, Float: 0.2},
+			wear:       csfloat.FieldTested,
+			normalized: 1.0 / 6,
+			rank:       0.05 / 0.23,
+			boundary:   0.15,
+			distance:   0.05,
+		},
+		{
+			name:       "howl well-worn",
+			item:       csfloat.Item{PaintIndex: 309, Float: 0.39},
+			wear:       csfloat.WellWorn,
+			normalized: 0.975,
+			rank:       0.5,
+			boundary:   0.38,
+			distance:   0.01,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ass := assert.New(t)
+			ass.Equal(testCase.wear, testCase.item.WearName())
+			normalized, ok := testCase.item.NormalizedFloat()
+			ass.True(ok)
+			ass.InDelta(testCase.normalized, normalized, 1e-9)
+			rank, ok := testCase.item.FloatRankInWear()
+			ass.True(ok)
+			ass.InDelta(testCase.rank, rank, 1e-9)
+
+			boundary, distance, ok := testCase.item.NearestWearBoundary()
+			if ass.True(ok) {
+				ass.Equal(testCase.boundary, boundary)
+				ass.InDelta(testCase.distance, distance, 1e-9)
+			}
+		})
+	}
+}
+
+func Test_ItemWearSmallCap(t *testing.T) {
+	ass := assert.New(t)
+	doppler := csfloat.Item{DefIndex: 507, PaintIndex: 418, Float: 0.01}
+	ass.Equal(csfloat.FactoryNew, doppler.WearName())
+
+	// Doppler's cap ends at 0.08, so 0.07 is the only boundary.
+	boundary, _, ok := doppler.NearestWearBoundary()
+	ass.True(ok)
+	ass.Equal(0.07, boundary)
+
+	low, high := doppler.FloatRange()
+	ass.Equal(0.0, low)
+	ass.Equal(0.07, high)
+
+	sticker := csfloat.Item{StickerIndex: 1}
+	ass.Empty(sticker.WearName())
+	_, _, ok = sticker.FloatCap()
+	ass.False(ok)
+}
+
+func Test_ItemWearUnknownCap(t *testing.T) {
+	ass := assert.New(t)
+	item := csfloat.Item{PaintIndex: 999999, Float: 0.5}
+	ass.Equal(csfloat.BattleScarred, item.WearName())
+
+	_, ok := item.NormalizedFloat()
+	ass.False(ok)
+	_, ok = item.FloatRankInWear()
+	ass.False(ok)
+	_, _, ok = item.NearestWearBoundary()
+	ass.False(ok)
+
+	low, high := item.FloatRange()
+	ass.Equal(0.45, low)
+	ass.Equal(1.0, high)
+}